@@ -3,6 +3,7 @@ package main
 import (
 	"errors"
 	"fmt"
+	"github.com/TurnsCoffeeIntoScripts/git-log-issue-finder/pkg/changelog"
 	"github.com/TurnsCoffeeIntoScripts/git-log-issue-finder/pkg/configuration"
 	"github.com/TurnsCoffeeIntoScripts/git-log-issue-finder/pkg/helpers"
 	"github.com/TurnsCoffeeIntoScripts/git-log-issue-finder/pkg/interpreter/evaluator"
@@ -10,9 +11,11 @@ import (
 	iobject "github.com/TurnsCoffeeIntoScripts/git-log-issue-finder/pkg/interpreter/object"
 	"github.com/TurnsCoffeeIntoScripts/git-log-issue-finder/pkg/interpreter/parser"
 	"github.com/TurnsCoffeeIntoScripts/git-log-issue-finder/pkg/interpreter/repl"
+	"github.com/TurnsCoffeeIntoScripts/git-log-issue-finder/pkg/output"
 	"github.com/TurnsCoffeeIntoScripts/git-log-issue-finder/pkg/script"
 	"log"
 	"os"
+	"strings"
 )
 
 func main() {
@@ -46,12 +49,21 @@ func start(glifParam configuration.GlifParameters) error {
 		input = &script.DiffLatestSemverWithLatestRCs
 	} else if helpers.IsBoolPtrTrue(glifParam.Scripts.UseDiffLatestSemver) {
 		input = &script.DiffLatestSemver
+	} else if helpers.IsBoolPtrTrue(glifParam.Scripts.UseDiffLatestSemverToHead) {
+		input = &script.DiffLatestSemverToHead
 	} else if helpers.IsBoolPtrTrue(glifParam.Scripts.UseUserSpecifiedScript) {
 		input = &glifParam.UserSpecifiedScript
 	} else {
 		return errors.New("unknown scripting mode")
 	}
 
+	// An invalid template is reported before running the script
+	if *glifParam.Output == configuration.OutputMarkdown {
+		if _, err := output.ParseTemplate(glifParam.ReleaseNotes); err != nil {
+			return err
+		}
+	}
+
 	l := lexer.New(*input)
 	p := parser.NewWithOptions(l, false)
 
@@ -61,13 +73,129 @@ func start(glifParam configuration.GlifParameters) error {
 		return fmt.Errorf("error parsing script")
 	}
 
-	env := iobject.NewEnvironmentWithParams(*glifParam.Tickets)
+	// The messages of the script must not corrupt the JSON document or the release notes
+	if *glifParam.Output != configuration.OutputText {
+		evaluator.Output = os.Stderr
+	}
+
+	env := newEnvironment(glifParam)
 	evaluated := evaluator.Eval(program, env)
 
 	switch evaluated.(type) {
 	case *iobject.Error:
 		return fmt.Errorf(fmt.Sprintf("%s", evaluated.Inspect()))
 	default:
+		if err := updateChangelog(glifParam, evaluated); err != nil {
+			return err
+		}
+
+		switch *glifParam.Output {
+		case configuration.OutputJSON:
+			return output.JSON(os.Stdout, evaluated)
+		case configuration.OutputMarkdown:
+			return output.Markdown(os.Stdout, evaluated, glifParam.ReleaseNotes)
+		default:
+			return output.Text(os.Stdout, evaluated)
+		}
+	}
+}
+
+// updateChangelog adds the section of the result to the changelog file, when one is specified. The message is printed
+// on the standard error like the messages of the script.
+func updateChangelog(glifParam configuration.GlifParameters, result iobject.Object) error {
+	if helpers.IsStringPtrNilOrEmtpy(glifParam.ChangelogFile) {
 		return nil
 	}
+
+	updated, err := changelog.UpdateFromResult(*glifParam.ChangelogFile, result, *glifParam.ChangelogVer)
+	if err != nil {
+		return err
+	}
+
+	if updated {
+		_, _ = fmt.Fprintf(evaluator.Output, "Added the new version to %s\n", *glifParam.ChangelogFile)
+	} else {
+		_, _ = fmt.Fprintf(evaluator.Output, "The version is already in %s\n", *glifParam.ChangelogFile)
+	}
+
+	return nil
+}
+
+// newEnvironment creates the environment of the script with the values specified via the input flags:
+//	- tickets (see configuration.GlifParameters.Tickets)
+//	- forcefetch (see configuration.GlifFlags.ForceFetch)
+//	- fetchopts, the options hash for the 'fetch' builtin
+//	- diffopts, the options hash for the 'diff' builtin (see diffOptions)
+//	- tagger, the identity used by the builtins writing tags and notes (tagger-name and tagger-email)
+func newEnvironment(glifParam configuration.GlifParameters) *iobject.Environment {
+	env := iobject.NewEnvironmentWithParams(*glifParam.Tickets)
+
+	forceFetch := evaluator.FALSE
+	if helpers.IsBoolPtrTrue(glifParam.Flags.ForceFetch) {
+		forceFetch = evaluator.TRUE
+	}
+	env.Set("forcefetch", forceFetch)
+
+	env.Set("fetchopts", iobject.NewStringHash(map[string]iobject.Object{
+		"remote":   &iobject.String{Value: *glifParam.FetchRemote},
+		"refspecs": stringArray(*glifParam.FetchRefSpecs),
+		"tags":     &iobject.Boolean{Value: helpers.IsBoolPtrTrue(glifParam.Flags.FetchTags)},
+		"prune":    &iobject.Boolean{Value: helpers.IsBoolPtrTrue(glifParam.Flags.FetchPrune)},
+	}))
+
+	env.Set("diffopts", diffOptions(glifParam))
+
+	env.Set("tagger", iobject.NewStringHash(map[string]iobject.Object{
+		"name":  &iobject.String{Value: *glifParam.TaggerName},
+		"email": &iobject.String{Value: *glifParam.TaggerEmail},
+	}))
+
+	return env
+}
+
+// diffOptions creates the options hash of the 'diff' builtin from the input flags. The details of the range are only
+// needed by the json output (with the commits of each ticket with output-commits), by the markdown output and by the
+// changelog (both always use the commits of each ticket).
+func diffOptions(glifParam configuration.GlifParameters) *iobject.Hash {
+	withChangelog := !helpers.IsStringPtrNilOrEmtpy(glifParam.ChangelogFile)
+	details := *glifParam.Output != configuration.OutputText || withChangelog
+	withCommits := *glifParam.Output == configuration.OutputMarkdown || withChangelog ||
+		(*glifParam.Output == configuration.OutputJSON && helpers.IsBoolPtrTrue(glifParam.Flags.OutCommits))
+
+	return iobject.NewStringHash(map[string]iobject.Object{
+		"include":    stringArray(*glifParam.IncludePaths),
+		"exclude":    stringArray(*glifParam.ExcludePaths),
+		"patchId":    &iobject.Boolean{Value: helpers.IsBoolPtrTrue(glifParam.Flags.PatchID)},
+		"notesRef":   &iobject.String{Value: *glifParam.NotesRef},
+		"trailers":   stringArray(*glifParam.Trailers),
+		"trackers":   stringArray(*glifParam.Trackers),
+		"ignore":     stringArray(*glifParam.Ignore),
+		"allow":      stringArray(strings.Join(glifParam.AllowedProjects, ",")),
+		"uppercase":  &iobject.Boolean{Value: !helpers.IsBoolPtrTrue(glifParam.Flags.KeepCase)},
+		"stripZeros": &iobject.Boolean{Value: helpers.IsBoolPtrTrue(glifParam.Flags.StripZeros)},
+		"aliases":    stringHash(glifParam.ProjectAliases),
+		"sides":      &iobject.String{Value: *glifParam.Sides},
+		"details":    &iobject.Boolean{Value: details},
+		"commits":    &iobject.Boolean{Value: withCommits},
+	})
+}
+
+// stringHash converts a map of strings to a hash
+func stringHash(values map[string]string) *iobject.Hash {
+	pairs := make(map[string]iobject.Object, len(values))
+	for key, value := range values {
+		pairs[key] = &iobject.String{Value: value}
+	}
+
+	return iobject.NewStringHash(pairs)
+}
+
+// stringArray converts a comma separated list to an array of strings, ignoring the empty values
+func stringArray(list string) *iobject.Array {
+	elements := make([]iobject.Object, 0)
+	for _, value := range helpers.SplitList(list) {
+		elements = append(elements, &iobject.String{Value: value})
+	}
+
+	return &iobject.Array{Elements: elements}
 }
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"fmt"
+	"github.com/TurnsCoffeeIntoScripts/git-log-issue-finder/pkg/interpreter/evaluator"
+	"github.com/TurnsCoffeeIntoScripts/git-log-issue-finder/pkg/resource"
+	"os"
+	"path/filepath"
+)
+
+// The resource is installed as /opt/resource/check, /opt/resource/in and /opt/resource/out, the command is the name
+// of the executable. It can also be run as 'glif-resource <command> [args]'.
+func main() {
+	command, args := filepath.Base(os.Args[0]), os.Args[1:]
+	if command != resource.Check && command != resource.In && command != resource.Out {
+		if len(args) == 0 {
+			_, _ = fmt.Fprintf(os.Stderr, "usage: %s check|in|out [directory]\n", command)
+			os.Exit(1)
+		}
+
+		command, args = args[0], args[1:]
+	}
+
+	// The standard output is the response of the resource
+	evaluator.Output = os.Stderr
+
+	if err := resource.Run(command, args, os.Stdin, os.Stdout); err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,240 @@
+// Package changelog adds the tickets of a release to a changelog following the Keep a Changelog format (see
+// https://keepachangelog.com/en/1.0.0/).
+//
+// The tickets are sorted by kind of change using the conventional commit type of their commits (see Kind):
+//	- Added: 'feat'
+//	- Fixed: 'fix'
+//	- Changed: any other type, or no type at all
+package changelog
+
+import (
+	"fmt"
+	"github.com/TurnsCoffeeIntoScripts/git-log-issue-finder/pkg/interpreter/object"
+	"github.com/TurnsCoffeeIntoScripts/git-log-issue-finder/pkg/output"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Kinds of changes of a section
+const (
+	Added   = "Added"
+	Changed = "Changed"
+	Fixed   = "Fixed"
+)
+
+// kinds are the kinds of changes in the order of the section
+var kinds = []string{Added, Changed, Fixed}
+
+// conventional matches the prefix of a conventional commit subject: the type, the optional scope and the optional
+// breaking change mark (ex: 'feat(parser)!: ')
+var conventional = regexp.MustCompile(`^([a-zA-Z]+)(?:\([^)]*\))?!?:\s*`)
+
+// unreleased matches the heading of the section of the unreleased changes
+var unreleased = regexp.MustCompile(`(?i)^##\s*\[unreleased\]`)
+
+// Section is the section of a version of the changelog
+type Section struct {
+	Version string
+	Date    time.Time
+	Changes map[string][]string // The entries of each kind of change
+}
+
+// Kind returns the kind of change of a commit subject and the subject without its conventional commit prefix
+func Kind(subject string) (string, string) {
+	match := conventional.FindStringSubmatch(subject)
+	if match == nil {
+		return Changed, subject
+	}
+
+	description := subject[len(match[0]):]
+	switch strings.ToLower(match[1]) {
+	case "feat":
+		return Added, description
+	case "fix":
+		return Fixed, description
+	default:
+		return Changed, description
+	}
+}
+
+// NewSection creates the section of the release notes. Each ticket is an entry of the kind of its commits: Added when
+// one of them is a feature, otherwise Fixed when one of them is a fix, otherwise Changed. The entry is described by
+// the subject of the first commit of the ticket.
+//
+// The version is the name of the tag ending the range when it isn't specified, without its 'v' prefix (ex: 'v1.2.0'
+// is the version '1.2.0'). The date is the date of the 'to' side of the range.
+func NewSection(notes *output.ReleaseNotes, version string) (*Section, error) {
+	if notes.To == nil {
+		return nil, fmt.Errorf("unable to date the changelog section: the range is unknown (see the 'details' option of 'diff')")
+	}
+
+	if version == "" {
+		if !notes.To.Tag {
+			return nil, fmt.Errorf("unable to name the changelog section: '%s' isn't a tag, the version must be specified",
+				notes.To.Name)
+		}
+
+		version = notes.To.Name
+		if len(version) > 1 && (version[0] == 'v' || version[0] == 'V') && version[1] >= '0' && version[1] <= '9' {
+			version = version[1:]
+		}
+	}
+
+	section := &Section{Version: version, Date: notes.To.Date, Changes: make(map[string][]string)}
+	for _, project := range notes.Projects {
+		for _, ticket := range project.Tickets {
+			kind, entry := ticketEntry(ticket)
+			section.Changes[kind] = append(section.Changes[kind], entry)
+		}
+	}
+
+	return section, nil
+}
+
+// ticketEntry returns the kind of change and the entry of the ticket (see NewSection). The commits are sorted from
+// the newest to the oldest.
+func ticketEntry(ticket output.Ticket) (string, string) {
+	if len(ticket.Commits) == 0 {
+		return Changed, ticket.Key
+	}
+
+	found := make(map[string]bool)
+	for _, c := range ticket.Commits {
+		kind, _ := Kind(c.Subject)
+		found[kind] = true
+	}
+
+	kind := Changed
+	if found[Added] {
+		kind = Added
+	} else if found[Fixed] {
+		kind = Fixed
+	}
+
+	_, description := Kind(ticket.Commits[len(ticket.Commits)-1].Subject)
+
+	// The key is often the first word of the subject (ex: 'ABC-12 add the exporter')
+	description = strings.TrimLeft(strings.TrimPrefix(description, ticket.Key), " :-")
+	if description == "" {
+		return kind, ticket.Key
+	}
+
+	return kind, ticket.Key + " " + description
+}
+
+// String formats the section: the '## [version] - date' heading followed by the entries of each kind of change
+func (section *Section) String() string {
+	var builder strings.Builder
+	builder.WriteString(section.heading() + " - " + section.Date.Format("2006-01-02") + "\n")
+
+	for _, kind := range kinds {
+		if len(section.Changes[kind]) == 0 {
+			continue
+		}
+
+		builder.WriteString("### " + kind + "\n")
+		for _, entry := range section.Changes[kind] {
+			builder.WriteString("- " + entry + "\n")
+		}
+	}
+
+	return builder.String()
+}
+
+func (section *Section) heading() string {
+	return "## [" + section.Version + "]"
+}
+
+// Update inserts the section in the changelog after the '## [Unreleased]' section (and its entries). The changelog
+// is returned unchanged when it already contains the section of the version, the second value is then false.
+func Update(changelog string, section *Section) (string, bool, error) {
+	lines := strings.Split(changelog, "\n")
+
+	start := -1
+	for i, line := range lines {
+		if strings.HasPrefix(line, section.heading()) {
+			return changelog, false, nil
+		}
+
+		if start < 0 && unreleased.MatchString(line) {
+			start = i
+		}
+	}
+
+	if start < 0 {
+		return "", false, fmt.Errorf("the changelog has no '## [Unreleased]' section")
+	}
+
+	end, next := len(lines), false
+	for i := start + 1; i < len(lines); i++ {
+		if strings.HasPrefix(lines[i], "## ") {
+			end, next = i, true
+			break
+		}
+	}
+
+	// The final new line of the changelog stays after the section
+	if !next && end-1 > start && lines[end-1] == "" {
+		end--
+	}
+
+	// The section is separated from the previous and the next sections by an empty line
+	insert := strings.Split(strings.TrimSuffix(section.String(), "\n"), "\n")
+	if strings.TrimSpace(lines[end-1]) != "" {
+		insert = append([]string{""}, insert...)
+	}
+
+	if next {
+		insert = append(insert, "")
+	}
+
+	updated := append(append(append([]string{}, lines[:end]...), insert...), lines[end:]...)
+	return strings.Join(updated, "\n"), true, nil
+}
+
+// UpdateFile inserts the section in the changelog file (see Update)
+func UpdateFile(path string, section *Section) (bool, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return false, fmt.Errorf("unable to read the changelog: %v", err)
+	}
+
+	updated, changed, err := Update(string(content), section)
+	if err != nil {
+		return false, fmt.Errorf("unable to update %s: %v", path, err)
+	}
+
+	if !changed {
+		return false, nil
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return false, fmt.Errorf("unable to read the changelog: %v", err)
+	}
+
+	if err := ioutil.WriteFile(path, []byte(updated), info.Mode()); err != nil {
+		return false, fmt.Errorf("unable to write the changelog: %v", err)
+	}
+
+	return true, nil
+}
+
+// UpdateFromResult inserts the section of the result of a script in the changelog file. The result is the value
+// returned by 'diff' with the 'details' option, and with the 'commits' option to sort the tickets (see NewSection).
+func UpdateFromResult(path string, result object.Object, version string) (bool, error) {
+	notes, err := output.NewReleaseNotes(result)
+	if err != nil {
+		return false, err
+	}
+
+	section, err := NewSection(notes, version)
+	if err != nil {
+		return false, err
+	}
+
+	return UpdateFile(path, section)
+}
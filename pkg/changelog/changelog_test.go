@@ -0,0 +1,166 @@
+package changelog
+
+import (
+	"github.com/TurnsCoffeeIntoScripts/git-log-issue-finder/pkg/output"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestKind(t *testing.T) {
+	tests := []struct {
+		subject     string
+		kind        string
+		description string
+	}{
+		{"feat: ABC-1 add the exporter", Added, "ABC-1 add the exporter"},
+		{"feat(export)!: ABC-1 drop the CSV format", Added, "ABC-1 drop the CSV format"},
+		{"Fix: ABC-2 handle empty input", Fixed, "ABC-2 handle empty input"},
+		{"refactor(parser): ABC-3 split the lexer", Changed, "ABC-3 split the lexer"},
+		{"ABC-4 update the README", Changed, "ABC-4 update the README"},
+		{"fixed: ABC-5 typo", Changed, "ABC-5 typo"},
+	}
+
+	for _, tt := range tests {
+		kind, description := Kind(tt.subject)
+		if kind != tt.kind || description != tt.description {
+			t.Errorf("%q: wrong kind. got=(%s, %q), want=(%s, %q)", tt.subject, kind, description, tt.kind,
+				tt.description)
+		}
+	}
+}
+
+func TestNewSection(t *testing.T) {
+	date := time.Date(2020, time.March, 4, 10, 30, 0, 0, time.UTC)
+	notes := &output.ReleaseNotes{
+		To: &output.Version{Name: "v1.2.0", Date: date, Tag: true},
+		Projects: []output.Project{
+			{Key: "ABC", Tickets: []output.Ticket{
+				{Key: "ABC-1", Commits: []output.Commit{{Subject: "fix: ABC-1 follow-up"}, {Subject: "feat: ABC-1 add the exporter"}}},
+				{Key: "ABC-2", Commits: []output.Commit{{Subject: "fix(parser): handle empty input (ABC-2)"}}},
+				{Key: "ABC-3", Commits: []output.Commit{{Subject: "ABC-3: update the README"}}},
+			}},
+			{Key: "XYZ", Tickets: []output.Ticket{{Key: "XYZ-4"}}},
+		},
+	}
+
+	section, err := NewSection(notes, "")
+	if err != nil {
+		t.Fatalf("NewSection returned an error: %v", err)
+	}
+
+	expected := `## [1.2.0] - 2020-03-04
+### Added
+- ABC-1 add the exporter
+### Changed
+- ABC-3 update the README
+- XYZ-4
+### Fixed
+- ABC-2 handle empty input (ABC-2)
+`
+	if section.String() != expected {
+		t.Errorf("wrong section.\ngot=%q\nwant=%q", section.String(), expected)
+	}
+
+	if section, err = NewSection(notes, "1.2.0-final"); err != nil || section.Version != "1.2.0-final" {
+		t.Errorf("wrong specified version. got=%+v (%v)", section, err)
+	}
+
+	notes.To = &output.Version{Name: "HEAD", Date: date}
+	expectedErr := "unable to name the changelog section: 'HEAD' isn't a tag, the version must be specified"
+	if _, err := NewSection(notes, ""); err == nil || err.Error() != expectedErr {
+		t.Errorf("wrong error without tag. got=%v, want=%q", err, expectedErr)
+	}
+
+	notes.To = nil
+	if _, err := NewSection(notes, "1.2.0"); err == nil {
+		t.Errorf("expected an error without range")
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	section := &Section{
+		Version: "1.1.0",
+		Date:    time.Date(2020, time.March, 4, 0, 0, 0, 0, time.UTC),
+		Changes: map[string][]string{Fixed: {"ABC-3 handle empty input"}},
+	}
+
+	tests := []struct {
+		name      string
+		changelog string
+		expected  string
+	}{
+		{"previous version",
+			"# Changelog\n\n## [Unreleased]\n### Added\n- Something\n\n## [1.0.0] - 2020-01-01\n",
+			"# Changelog\n\n## [Unreleased]\n### Added\n- Something\n\n## [1.1.0] - 2020-03-04\n### Fixed\n" +
+				"- ABC-3 handle empty input\n\n## [1.0.0] - 2020-01-01\n"},
+		{"no empty line before the previous version",
+			"## [Unreleased] \n- Something\n## [1.0.0] - 2020-01-01",
+			"## [Unreleased] \n- Something\n\n## [1.1.0] - 2020-03-04\n### Fixed\n- ABC-3 handle empty input\n\n" +
+				"## [1.0.0] - 2020-01-01"},
+		{"first version",
+			"# Changelog\n\n## [Unreleased]\n",
+			"# Changelog\n\n## [Unreleased]\n\n## [1.1.0] - 2020-03-04\n### Fixed\n- ABC-3 handle empty input\n"},
+		{"first version without final new line",
+			"## [unreleased]",
+			"## [unreleased]\n\n## [1.1.0] - 2020-03-04\n### Fixed\n- ABC-3 handle empty input"},
+	}
+
+	for _, tt := range tests {
+		updated, changed, err := Update(tt.changelog, section)
+		if err != nil {
+			t.Fatalf("%s: Update returned an error: %v", tt.name, err)
+		}
+
+		if !changed || updated != tt.expected {
+			t.Errorf("%s: wrong changelog (changed=%t).\ngot=%q\nwant=%q", tt.name, changed, updated, tt.expected)
+		}
+
+		// The section is only added once
+		again, changed, err := Update(updated, section)
+		if err != nil || changed || again != updated {
+			t.Errorf("%s: the changelog changed again (changed=%t, err=%v).\ngot=%q", tt.name, changed, err, again)
+		}
+	}
+
+	if _, _, err := Update("# Changelog\n\n## [1.0.0] - 2020-01-01\n", section); err == nil ||
+		err.Error() != "the changelog has no '## [Unreleased]' section" {
+		t.Errorf("wrong error without unreleased section. got=%v", err)
+	}
+}
+
+func TestUpdateFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "glif-changelog-")
+	if err != nil {
+		t.Fatalf("unable to create temporary directory: %v", err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	path := filepath.Join(dir, "CHANGELOG.md")
+	if err := ioutil.WriteFile(path, []byte("## [Unreleased]\n"), 0644); err != nil {
+		t.Fatalf("unable to write %s: %v", path, err)
+	}
+
+	section := &Section{Version: "1.1.0", Date: time.Date(2020, time.March, 4, 0, 0, 0, 0, time.UTC)}
+	for i, expected := range []bool{true, false} {
+		changed, err := UpdateFile(path, section)
+		if err != nil || changed != expected {
+			t.Errorf("update %d: wrong result. got=(%t, %v), want=%t", i, changed, err, expected)
+		}
+	}
+
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("unable to read %s: %v", path, err)
+	}
+
+	if string(content) != "## [Unreleased]\n\n## [1.1.0] - 2020-03-04\n" {
+		t.Errorf("wrong changelog. got=%q", string(content))
+	}
+
+	if _, err := UpdateFile(filepath.Join(dir, "missing.md"), section); err == nil {
+		t.Errorf("expected an error for a missing changelog")
+	}
+}
@@ -18,34 +18,120 @@ package configuration
 
 import (
 	"flag"
+	"fmt"
 	"github.com/TurnsCoffeeIntoScripts/git-log-issue-finder/pkg/helpers"
+	"github.com/TurnsCoffeeIntoScripts/git-log-issue-finder/pkg/issue"
 	"io/ioutil"
+	"os"
 	"reflect"
 )
 
 // Definition of constants that are use for the 'flag' setup
 const (
 	// Parameters
-	script  = "script"
-	tickets = "tickets"
+	script        = "script"
+	tickets       = "tickets"
+	fetchRemote   = "fetch-remote"
+	fetchRefSpecs = "fetch-refspecs"
+	includePaths  = "include-paths"
+	excludePaths  = "exclude-paths"
+	notesRef      = "notes-ref"
+	taggerName    = "tagger-name"
+	taggerEmail   = "tagger-email"
+	trailers      = "trailers"
+	trackers      = "trackers"
+	ignore        = "ignore"
+	allowProjects = "allow-projects"
+	allowFile     = "allow-projects-file"
+	aliases       = "aliases"
+	aliasesFile   = "aliases-file"
+	sides         = "sides"
+	output        = "output"
+	templateFile  = "template"
+	changelogFile = "changelog"
+	changelogVer  = "changelog-version"
 
 	// Flags
 	repl       = "repl"
 	forceFetch = "force-fetch"
+	fetchPrune = "fetch-prune"
+	fetchTags  = "fetch-tags"
+	patchID    = "patch-id"
+	keepCase   = "keep-case"
+	stripZeros = "strip-zeros"
+	outCommits = "output-commits"
 
 	// Pre configured scripts
 	diffLatestSemverWithLatestBuilds = "semver-latest-builds"
 	diffLatestSemverWithLatestRCs    = "semver-latest-rcs"
 	diffLatestSemver                 = "semver-latest"
+	diffLatestSemverToHead           = "semver-to-head"
 
 	// Default values and descriptions for both paramaters and flags
-	scriptDefault         = ""
-	scriptDescription     = "The glif script file to execute"
-	ticketsDefault        = "*"
-	ticketsDescription    = "The Jira tickets regex used to search the repo's log"
-	replDescription       = "Enter the Read-Eval-Print-Loop"
-	forceFetchDefault     = false
-	forceFetchDescription = "Force a 'git fetch' operation on the specified repository"
+	scriptDefault            = ""
+	scriptDescription        = "The glif script file to execute"
+	ticketsDefault           = "*"
+	ticketsDescription       = "Comma separated Jira project keys or regexes (ex: ABC,XY[ZW]) used to search the repo's log, * matches every project except the technical tokens like UTF-8 (unless allowed with allow-projects)"
+	replDescription          = "Enter the Read-Eval-Print-Loop"
+	forceFetchDefault        = false
+	forceFetchDescription    = "Force a 'git fetch' operation on the specified repository"
+	fetchRemoteDefault       = "origin"
+	fetchRemoteDescription   = "The remote used by the 'git fetch' operation (see force-fetch)"
+	fetchRefSpecsDefault     = ""
+	fetchRefSpecsDescription = "Comma separated refspecs used by the 'git fetch' operation instead of the remote's (see force-fetch)"
+	fetchPruneDefault        = false
+	fetchPruneDescription    = "Remove the references that no longer exist on the remote when fetching (see force-fetch)"
+	fetchTagsDefault         = true
+	fetchTagsDescription     = "Fetch every tag of the remote when fetching (see force-fetch)"
+	includePathsDefault      = ""
+	includePathsDescription  = "Comma separated globs (ex: services/service-a/**), only the commits modifying a matching path are diffed"
+	excludePathsDefault      = ""
+	excludePathsDescription  = "Comma separated globs, the changes to a matching path are ignored by the diff"
+	notesRefDefault          = "refs/notes/commits"
+	notesRefDescription      = "The notes reference scanned for tickets on top of the commit messages (see 'git notes')"
+	taggerNameDefault        = ""
+	taggerNameDescription    = "The name of the tagger of the tags and notes written by the scripts (see the 'tagger' variable)"
+	taggerEmailDefault       = ""
+	taggerEmailDescription   = "The email of the tagger of the tags and notes written by the scripts (see the 'tagger' variable)"
+	trailersDefault          = ""
+	trailersDescription      = "Comma separated trailer keys (ex: Refs,Fixes), only the values of these trailers are scanned instead of the whole commit message"
+	trackersDefault          = "jira"
+	trackersDescription      = "Comma separated issue trackers whose keys are searched: jira (see tickets), github (#123, org/repo#123), gitlab (!45) and azure (AB#678)"
+	ignoreDefault            = ""
+	ignoreDescription        = "Comma separated keys (ex: ABC-12) or projects (ex: TMP, org/repo) that are never reported as tickets"
+	allowProjectsDefault     = ""
+	allowProjectsDescription = "Comma separated known Jira project keys, the keys of the other projects are ignored"
+	allowFileDefault         = ""
+	allowFileDescription     = "File listing the known Jira project keys, one per line (see allow-projects)"
+	aliasesDefault           = ""
+	aliasesDescription       = "Comma separated aliases of the renamed Jira projects (ex: OLDKEY=NEWKEY), OLDKEY-5 is reported as NEWKEY-5"
+	aliasesFileDefault       = ""
+	aliasesFileDescription   = "File listing the aliases of the renamed Jira projects, one OLDKEY=NEWKEY per line (see aliases)"
+	sidesDefault             = ""
+	sidesDescription         = "Print the tickets new in the 'to' side of the diff (added), the ones only in the 'from' side (removed) or both, with labels"
+	outputDefault            = OutputText
+	outputDescription        = "The format of the result: text (the list of tickets), json (a versioned document with the range and its tickets) or markdown (release notes, see template)"
+	templateFileDefault      = ""
+	templateFileDescription  = "The Go template (text/template) of the markdown output, replacing the default release notes"
+	outCommitsDefault        = false
+	outCommitsDescription    = "Add the commits referencing each ticket to the json output (see output)"
+	changelogFileDefault     = ""
+	changelogFileDescription = "Keep a Changelog file in which the section of the version ending the diff is added below '## [Unreleased]', unless it already exists"
+	changelogVerDefault      = ""
+	changelogVerDescription  = "The version of the section added to the changelog, the name of the tag ending the diff by default (see changelog)"
+	keepCaseDefault          = false
+	keepCaseDescription      = "Keep the Jira keys as they were written instead of converting them to upper case"
+	stripZerosDefault        = false
+	stripZerosDescription    = "Remove the leading zeros of the issue numbers (ex: ABC-012 is reported as ABC-12)"
+	patchIDDefault           = false
+	patchIDDescription       = "Compare the commits by patch-id so that the cherry-picked commits are considered as already released"
+)
+
+// Output formats of the result of the script (see the 'output' parameter)
+const (
+	OutputText     = "text"
+	OutputJSON     = "json"
+	OutputMarkdown = "markdown"
 )
 
 // GlifParameters contains the various flags that were given via the program's input paramters
@@ -53,19 +139,47 @@ const (
 //   - see: configuration.GlifFlags
 //	 - see: configuration.GlifPreConfiguredScripts
 type GlifParameters struct {
-	Script  *string
-	Tickets *string
+	Script        *string
+	Tickets       *string
+	FetchRemote   *string
+	FetchRefSpecs *string
+	IncludePaths  *string
+	ExcludePaths  *string
+	NotesRef      *string
+	TaggerName    *string
+	TaggerEmail   *string
+	Trailers      *string
+	Trackers      *string
+	Ignore        *string
+	AllowProjects *string
+	AllowFile     *string
+	Aliases       *string
+	AliasesFile   *string
+	Sides         *string
+	Output        *string
+	TemplateFile  *string
+	ChangelogFile *string
+	ChangelogVer  *string
 
 	Flags   GlifFlags
 	Scripts GlifPreConfiguredScripts
 
 	UserSpecifiedScript string
+	AllowedProjects     []string          // The projects of AllowProjects and of the AllowFile
+	ProjectAliases      map[string]string // The aliases of Aliases and of the AliasesFile
+	ReleaseNotes        string            // The content of the TemplateFile, empty for the default template
 }
 
 // GlifFlags contains the various boolean flags (actual command line flags and not parameters) used by glif.
 type GlifFlags struct {
 	REPL       *bool
 	ForceFetch *bool
+	FetchPrune *bool
+	FetchTags  *bool
+	PatchID    *bool
+	KeepCase   *bool
+	StripZeros *bool
+	OutCommits *bool
 }
 
 // GlifPreConfiguredScripts contains only boolean flags that specify if a "preconfigured" script should be used.
@@ -82,28 +196,82 @@ type GlifPreConfiguredScripts struct {
 func (params *GlifParameters) Parse(forceRepl bool) bool {
 	params.Script = flag.String(script, scriptDefault, scriptDescription)
 	params.Tickets = flag.String(tickets, ticketsDefault, ticketsDescription)
+	params.FetchRemote = flag.String(fetchRemote, fetchRemoteDefault, fetchRemoteDescription)
+	params.FetchRefSpecs = flag.String(fetchRefSpecs, fetchRefSpecsDefault, fetchRefSpecsDescription)
+	params.IncludePaths = flag.String(includePaths, includePathsDefault, includePathsDescription)
+	params.ExcludePaths = flag.String(excludePaths, excludePathsDefault, excludePathsDescription)
+	params.NotesRef = flag.String(notesRef, notesRefDefault, notesRefDescription)
+	params.TaggerName = flag.String(taggerName, taggerNameDefault, taggerNameDescription)
+	params.TaggerEmail = flag.String(taggerEmail, taggerEmailDefault, taggerEmailDescription)
+	params.Trailers = flag.String(trailers, trailersDefault, trailersDescription)
+	params.Trackers = flag.String(trackers, trackersDefault, trackersDescription)
+	params.Ignore = flag.String(ignore, ignoreDefault, ignoreDescription)
+	params.AllowProjects = flag.String(allowProjects, allowProjectsDefault, allowProjectsDescription)
+	params.AllowFile = flag.String(allowFile, allowFileDefault, allowFileDescription)
+	params.Aliases = flag.String(aliases, aliasesDefault, aliasesDescription)
+	params.AliasesFile = flag.String(aliasesFile, aliasesFileDefault, aliasesFileDescription)
+	params.Sides = flag.String(sides, sidesDefault, sidesDescription)
+	params.Output = flag.String(output, outputDefault, outputDescription)
+	params.TemplateFile = flag.String(templateFile, templateFileDefault, templateFileDescription)
+	params.ChangelogFile = flag.String(changelogFile, changelogFileDefault, changelogFileDescription)
+	params.ChangelogVer = flag.String(changelogVer, changelogVerDefault, changelogVerDescription)
 
 	params.Flags.REPL = flag.Bool(repl, forceRepl, replDescription)
 	params.Flags.ForceFetch = flag.Bool(forceFetch, forceFetchDefault, forceFetchDescription)
+	params.Flags.FetchPrune = flag.Bool(fetchPrune, fetchPruneDefault, fetchPruneDescription)
+	params.Flags.FetchTags = flag.Bool(fetchTags, fetchTagsDefault, fetchTagsDescription)
+	params.Flags.PatchID = flag.Bool(patchID, patchIDDefault, patchIDDescription)
+	params.Flags.KeepCase = flag.Bool(keepCase, keepCaseDefault, keepCaseDescription)
+	params.Flags.StripZeros = flag.Bool(stripZeros, stripZerosDefault, stripZerosDescription)
+	params.Flags.OutCommits = flag.Bool(outCommits, outCommitsDefault, outCommitsDescription)
 
 	params.Scripts.UseDiffLatestSemverWithLatestBuilds = flag.Bool(diffLatestSemverWithLatestBuilds, false, "script.DiffLatestSemverWithLatestBuilds")
 	params.Scripts.UseDiffLatestSemverWithLatestRCs = flag.Bool(diffLatestSemverWithLatestRCs, false, "script.DiffLatestSemverWithLatestRCs")
 	params.Scripts.UseDiffLatestSemver = flag.Bool(diffLatestSemver, false, "script.DiffLatestSemver")
+	params.Scripts.UseDiffLatestSemverToHead = flag.Bool(diffLatestSemverToHead, false, "script.DiffLatestSemverToHead")
 
 	flag.Parse()
 
-	var ok bool
-	if ok = params.validate(); !ok {
+	if err := params.validate(); err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "%v\n", err)
 		return false
 	}
 
-	return ok
+	return true
 }
 
-func (params *GlifParameters) validate() bool {
+func (params *GlifParameters) validate() error {
 	// If REPL was specified we skip the rest of the validation because glif will be set in interactive mode
 	if helpers.IsBoolPtrTrue(params.Flags.REPL) {
-		return true
+		return nil
+	}
+
+	if err := params.validatePatterns(); err != nil {
+		return err
+	}
+
+	if params.Sides != nil {
+		switch *params.Sides {
+		case "", "added", "removed", "both":
+		default:
+			return fmt.Errorf("invalid %s parameter '%s' (expected 'added', 'removed' or 'both')", sides, *params.Sides)
+		}
+	}
+
+	if err := params.validateOutput(); err != nil {
+		return err
+	}
+
+	if err := params.validateChangelog(); err != nil {
+		return err
+	}
+
+	if err := params.readAllowedProjects(); err != nil {
+		return err
+	}
+
+	if err := params.readProjectAliases(); err != nil {
+		return err
 	}
 
 	if helpers.IsStringPtrNilOrEmtpy(params.Script) {
@@ -121,17 +289,127 @@ func (params *GlifParameters) validate() bool {
 			}
 		}
 
-		return count == 1
+		if count != 1 {
+			return fmt.Errorf("exactly one script must be specified (the %s parameter or a predefined script), got %d",
+				script, count)
+		}
+
+		return nil
 	}
 
 	buffer, err := ioutil.ReadFile(*params.Script)
 	if err != nil {
-		return false
+		return fmt.Errorf("unable to read the script: %v", err)
 	}
 
 	params.UserSpecifiedScript = string(buffer)
 	forceTrue := true
 	params.Scripts.UseUserSpecifiedScript = &forceTrue
 
-	return true
+	return nil
+}
+
+// validatePatterns checks that the tickets regex and the trackers are valid (see issue.NewMatcher)
+func (params *GlifParameters) validatePatterns() error {
+	if params.Trackers != nil {
+		if _, err := issue.NewMatcher(issue.AnyProject, helpers.SplitList(*params.Trackers), nil, nil); err != nil {
+			return fmt.Errorf("invalid %s parameter: %v", trackers, err)
+		}
+	}
+
+	if params.Tickets != nil {
+		if _, err := issue.NewMatcher(*params.Tickets, nil, nil, nil); err != nil {
+			return fmt.Errorf("invalid %s parameter: %v", tickets, err)
+		}
+	}
+
+	return nil
+}
+
+// validateOutput checks the output format, then reads the template of the markdown output (it is parsed by the command
+// line before running the script, see output.ParseTemplate)
+func (params *GlifParameters) validateOutput() error {
+	if params.Output != nil {
+		switch *params.Output {
+		case OutputText, OutputJSON, OutputMarkdown:
+		default:
+			return fmt.Errorf("invalid %s parameter '%s' (expected '%s', '%s' or '%s')", output, *params.Output,
+				OutputText, OutputJSON, OutputMarkdown)
+		}
+	}
+
+	if helpers.IsStringPtrNilOrEmtpy(params.TemplateFile) {
+		return nil
+	}
+
+	if params.Output == nil || *params.Output != OutputMarkdown {
+		return fmt.Errorf("the %s parameter requires the %s output (--%s=%s)", templateFile, OutputMarkdown, output,
+			OutputMarkdown)
+	}
+
+	buffer, err := ioutil.ReadFile(*params.TemplateFile)
+	if err != nil {
+		return fmt.Errorf("unable to read the %s: %v", templateFile, err)
+	}
+
+	params.ReleaseNotes = string(buffer)
+	return nil
+}
+
+// validateChangelog checks that the version added to the changelog comes with the changelog file
+func (params *GlifParameters) validateChangelog() error {
+	if !helpers.IsStringPtrNilOrEmtpy(params.ChangelogVer) && helpers.IsStringPtrNilOrEmtpy(params.ChangelogFile) {
+		return fmt.Errorf("the %s parameter requires the %s parameter", changelogVer, changelogFile)
+	}
+
+	return nil
+}
+
+// readAllowedProjects combines the projects of the allow-projects parameter with the ones listed in the
+// allow-projects-file
+func (params *GlifParameters) readAllowedProjects() error {
+	params.AllowedProjects = make([]string, 0)
+	if params.AllowProjects != nil {
+		params.AllowedProjects = helpers.SplitList(*params.AllowProjects)
+	}
+
+	if helpers.IsStringPtrNilOrEmtpy(params.AllowFile) {
+		return nil
+	}
+
+	projects, err := issue.ReadList(*params.AllowFile)
+	if err != nil {
+		return fmt.Errorf("unable to read the %s: %v", allowFile, err)
+	}
+
+	params.AllowedProjects = append(params.AllowedProjects, projects...)
+	return nil
+}
+
+// readProjectAliases combines the aliases of the aliases parameter with the ones listed in the aliases-file
+func (params *GlifParameters) readProjectAliases() error {
+	params.ProjectAliases = make(map[string]string)
+	if params.Aliases != nil {
+		parsed, err := issue.ParseAliases(helpers.SplitList(*params.Aliases), "the "+aliases+" parameter")
+		if err != nil {
+			return err
+		}
+
+		params.ProjectAliases = parsed
+	}
+
+	if helpers.IsStringPtrNilOrEmtpy(params.AliasesFile) {
+		return nil
+	}
+
+	fromFile, err := issue.ReadAliases(*params.AliasesFile)
+	if err != nil {
+		return fmt.Errorf("unable to read the %s: %v", aliasesFile, err)
+	}
+
+	for project, alias := range fromFile {
+		params.ProjectAliases[project] = alias
+	}
+
+	return nil
 }
@@ -0,0 +1,95 @@
+package configuration
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func stringPtr(value string) *string {
+	return &value
+}
+
+func boolPtr(value bool) *bool {
+	return &value
+}
+
+func TestValidate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "glif-configuration-")
+	if err != nil {
+		t.Fatalf("unable to create temporary directory: %v", err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	aliasesPath := filepath.Join(dir, "aliases.txt")
+	if err := ioutil.WriteFile(aliasesPath, []byte("OLDKEY\n"), 0644); err != nil {
+		t.Fatalf("unable to write %s: %v", aliasesPath, err)
+	}
+
+	templatePath := filepath.Join(dir, "notes.tmpl")
+	if err := ioutil.WriteFile(templatePath, []byte("{{range .Tickets}}- {{.}}\n{{end}}"), 0644); err != nil {
+		t.Fatalf("unable to write %s: %v", templatePath, err)
+	}
+
+	tests := []struct {
+		name     string
+		params   GlifParameters
+		expected string
+	}{
+		{"valid", GlifParameters{Tickets: stringPtr("ABC,A{1,3}"), Trackers: stringPtr("jira,github")}, ""},
+		{"invalid tickets", GlifParameters{Tickets: stringPtr("ABC,AB[C")},
+			"invalid tickets parameter: invalid ticket pattern 'AB[C' in 'ABC,AB[C': error parsing regexp: missing closing ]: `[C`"},
+		{"unknown tracker", GlifParameters{Tickets: stringPtr("*"), Trackers: stringPtr("jira,trello")},
+			"invalid trackers parameter: unknown issue tracker 'trello' (expected one of azure, github, gitlab, jira)"},
+		{"valid sides", GlifParameters{Sides: stringPtr("both")}, ""},
+		{"invalid sides", GlifParameters{Sides: stringPtr("all")},
+			"invalid sides parameter 'all' (expected 'added', 'removed' or 'both')"},
+		{"json output", GlifParameters{Output: stringPtr("json")}, ""},
+		{"invalid output", GlifParameters{Output: stringPtr("xml")},
+			"invalid output parameter 'xml' (expected 'text', 'json' or 'markdown')"},
+		{"markdown template", GlifParameters{Output: stringPtr("markdown"), TemplateFile: stringPtr(templatePath)}, ""},
+		{"template without markdown", GlifParameters{Output: stringPtr("json"), TemplateFile: stringPtr(templatePath)},
+			"the template parameter requires the markdown output (--output=markdown)"},
+		{"changelog version", GlifParameters{ChangelogFile: stringPtr("CHANGELOG.md"), ChangelogVer: stringPtr("1.2.0")}, ""},
+		{"changelog version without changelog", GlifParameters{ChangelogVer: stringPtr("1.2.0")},
+			"the changelog-version parameter requires the changelog parameter"},
+		{"missing template", GlifParameters{Output: stringPtr("markdown"),
+			TemplateFile: stringPtr(filepath.Join(dir, "missing.tmpl"))},
+			"unable to read the template: open " + filepath.Join(dir, "missing.tmpl") + ": no such file or directory"},
+		{"invalid alias", GlifParameters{Aliases: stringPtr("OLDKEY=NEWKEY,LEGACY")},
+			"invalid alias 'LEGACY' in the aliases parameter: expected OLDKEY=NEWKEY"},
+		{"invalid aliases file", GlifParameters{AliasesFile: stringPtr(aliasesPath)},
+			"unable to read the aliases-file: invalid alias 'OLDKEY' in " + aliasesPath + ": expected OLDKEY=NEWKEY"},
+		{"missing script", GlifParameters{Script: stringPtr(filepath.Join(dir, "missing.glif"))},
+			"unable to read the script: open " + filepath.Join(dir, "missing.glif") + ": no such file or directory"},
+	}
+
+	for _, tt := range tests {
+		params := tt.params
+		params.Scripts.UseDiffLatestSemver = boolPtr(true)
+
+		err := params.validate()
+		if tt.expected == "" {
+			if err != nil {
+				t.Errorf("%s: validate returned an error: %v", tt.name, err)
+			}
+			continue
+		}
+
+		if err == nil || err.Error() != tt.expected {
+			t.Errorf("%s: wrong error. got=%v, want=%q", tt.name, err, tt.expected)
+		}
+	}
+
+	params := GlifParameters{}
+	expected := "exactly one script must be specified (the script parameter or a predefined script), got 0"
+	if err := params.validate(); err == nil || err.Error() != expected {
+		t.Errorf("wrong error without script. got=%v, want=%q", err, expected)
+	}
+
+	params = GlifParameters{Flags: GlifFlags{REPL: boolPtr(true)}, Tickets: stringPtr("AB[C")}
+	if err := params.validate(); err != nil {
+		t.Errorf("the REPL should not be validated. got=%v", err)
+	}
+}
@@ -1,17 +1,15 @@
 package helpers
 
-import (
-	"github.com/go-git/go-git/v5/plumbing/object"
-	"strings"
-)
+import "strings"
 
-// Contains check if a tag is present in an array by name. If so it returns that element
-func Contains(arr []*object.Tag, elemName string) (*object.Tag, bool) {
-	for idx := range arr {
-		if strings.Contains(arr[idx].Name, elemName) {
-			return arr[idx], true
+// SplitList splits a comma separated list, ignoring the empty values
+func SplitList(list string) []string {
+	values := make([]string, 0)
+	for _, value := range strings.Split(list, ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
 		}
 	}
 
-	return nil, false
+	return values
 }
@@ -0,0 +1,146 @@
+// Package testrepo creates the git repositories on which the tests of the other packages run.
+//
+// A Repository is created in a temporary directory with go-git. The commits and the tags are dated by a clock that
+// moves forward by one minute each time, so that the dates (and the order by date) are predictable. The git command
+// line is also available for what go-git doesn't do (ex: the git notes, 'git clone --bare').
+package testrepo
+
+import (
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// Repository is a git repository created for a test, the test fails at the first error
+type Repository struct {
+	Dir     string
+	GitRepo *git.Repository
+
+	tb    testing.TB
+	clock time.Time
+}
+
+// New initializes a repository in a new temporary directory, removed by Cleanup
+func New(tb testing.TB) *Repository {
+	dir, err := ioutil.TempDir("", "glif-test-")
+	if err != nil {
+		tb.Fatalf("unable to create temporary directory: %v", err)
+	}
+
+	repo, err := git.PlainInit(dir, false)
+	if err != nil {
+		tb.Fatalf("unable to init repository: %v", err)
+	}
+
+	return &Repository{Dir: dir, GitRepo: repo, tb: tb, clock: time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)}
+}
+
+// Open opens an existing repository (ex: created by 'git clone'), it isn't removed by Cleanup
+func Open(tb testing.TB, dir string) *Repository {
+	repo, err := git.PlainOpen(dir)
+	if err != nil {
+		tb.Fatalf("unable to open repository: %v", err)
+	}
+
+	return &Repository{Dir: dir, GitRepo: repo, tb: tb, clock: time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)}
+}
+
+// Cleanup removes the directory of a repository created by New
+func (r *Repository) Cleanup() {
+	_ = os.RemoveAll(r.Dir)
+}
+
+// Signature returns the identity of the commits and the tags, one minute after the previous one
+func (r *Repository) Signature() *object.Signature {
+	r.clock = r.clock.Add(time.Minute)
+	return &object.Signature{Name: "glif", Email: "glif@example.com", When: r.clock}
+}
+
+// Commit writes the message in each of the specified files (or in 'file.txt' if none are given) and commits them
+func (r *Repository) Commit(message string, files ...string) plumbing.Hash {
+	if len(files) == 0 {
+		files = []string{"file.txt"}
+	}
+
+	contents := make(map[string]string, len(files))
+	for _, file := range files {
+		contents[file] = message + "\n"
+	}
+
+	return r.CommitContents(message, contents)
+}
+
+// CommitContents writes the content of each file and commits them
+func (r *Repository) CommitContents(message string, contents map[string]string) plumbing.Hash {
+	w, err := r.GitRepo.Worktree()
+	if err != nil {
+		r.tb.Fatalf("unable to get worktree: %v", err)
+	}
+
+	for file, content := range contents {
+		path := filepath.Join(r.Dir, file)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			r.tb.Fatalf("unable to create directory for %s: %v", file, err)
+		}
+
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			r.tb.Fatalf("unable to write %s: %v", file, err)
+		}
+
+		if _, err := w.Add(file); err != nil {
+			r.tb.Fatalf("unable to add %s: %v", file, err)
+		}
+	}
+
+	hash, err := w.Commit(message, &git.CommitOptions{Author: r.Signature()})
+	if err != nil {
+		r.tb.Fatalf("unable to commit: %v", err)
+	}
+
+	return hash
+}
+
+// Checkout creates the branch at the specified commit and checks it out
+func (r *Repository) Checkout(branch string, hash plumbing.Hash) {
+	w, err := r.GitRepo.Worktree()
+	if err != nil {
+		r.tb.Fatalf("unable to get worktree: %v", err)
+	}
+
+	opts := &git.CheckoutOptions{Hash: hash, Branch: plumbing.NewBranchReferenceName(branch), Create: true}
+	if err := w.Checkout(opts); err != nil {
+		r.tb.Fatalf("unable to checkout %s: %v", branch, err)
+	}
+}
+
+// AnnotatedTag creates an annotated tag on the commit
+func (r *Repository) AnnotatedTag(name string, hash plumbing.Hash) {
+	opts := &git.CreateTagOptions{Tagger: r.Signature(), Message: "Tagging version " + name}
+	if _, err := r.GitRepo.CreateTag(name, hash, opts); err != nil {
+		r.tb.Fatalf("unable to create annotated tag %s: %v", name, err)
+	}
+}
+
+// LightweightTag creates a lightweight tag on the commit
+func (r *Repository) LightweightTag(name string, hash plumbing.Hash) {
+	if _, err := r.GitRepo.CreateTag(name, hash, nil); err != nil {
+		r.tb.Fatalf("unable to create lightweight tag %s: %v", name, err)
+	}
+}
+
+// Git runs the git command line in the repository and returns its output
+func (r *Repository) Git(args ...string) string {
+	args = append([]string{"-C", r.Dir, "-c", "user.name=glif", "-c", "user.email=glif@example.com"}, args...)
+	out, err := exec.Command("git", args...).CombinedOutput()
+	if err != nil {
+		r.tb.Fatalf("git %v failed: %v\n%s", args, err, out)
+	}
+
+	return string(out)
+}
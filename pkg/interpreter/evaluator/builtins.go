@@ -3,10 +3,11 @@ package evaluator
 import (
 	"bytes"
 	"fmt"
+	"github.com/TurnsCoffeeIntoScripts/git-log-issue-finder/pkg/changelog"
 	"github.com/TurnsCoffeeIntoScripts/git-log-issue-finder/pkg/interpreter/object"
-	"github.com/go-git/go-git/v5"
-	gitobject "github.com/go-git/go-git/v5/plumbing/object"
-	"regexp"
+	"github.com/TurnsCoffeeIntoScripts/git-log-issue-finder/pkg/issue"
+	"github.com/TurnsCoffeeIntoScripts/git-log-issue-finder/pkg/output"
+	"github.com/TurnsCoffeeIntoScripts/git-log-issue-finder/pkg/scl"
 	"strings"
 )
 
@@ -111,12 +112,55 @@ var builtins = map[string]*object.Builtin{
 	"print": {
 		Fn: func(args ...object.Object) object.Object {
 			for _, arg := range args {
-				fmt.Println(arg.Inspect())
+				_, _ = fmt.Fprintln(Output, arg.Inspect())
 			}
 
 			return NULL
 		},
 	},
+	"readList": {
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newError("wrong number of arguments. got=%d, want=1", len(args))
+			}
+
+			path, ok := args[0].(*object.String)
+			if !ok {
+				return newError("Unable to convert args[0] to *object.String while executing 'readList'")
+			}
+
+			values, err := issue.ReadList(path.Value)
+			if err != nil {
+				return newError("%s while executing 'readList'", err.Error())
+			}
+
+			return stringArray(values)
+		},
+	},
+	"readAliases": {
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newError("wrong number of arguments. got=%d, want=1", len(args))
+			}
+
+			path, ok := args[0].(*object.String)
+			if !ok {
+				return newError("Unable to convert args[0] to *object.String while executing 'readAliases'")
+			}
+
+			aliases, err := issue.ReadAliases(path.Value)
+			if err != nil {
+				return newError("%s while executing 'readAliases'", err.Error())
+			}
+
+			pairs := make(map[string]object.Object, len(aliases))
+			for project, alias := range aliases {
+				pairs[project] = &object.String{Value: alias}
+			}
+
+			return object.NewStringHash(pairs)
+		},
+	},
 	"initRepo": {
 		Fn: func(args ...object.Object) object.Object {
 			if len(args) != 1 {
@@ -124,14 +168,61 @@ var builtins = map[string]*object.Builtin{
 			}
 
 			repoObj := &object.Repo{Path: args[0]}
-			repoObj.Repo.Open(args[0].Inspect())
-			repoObj.Repo.InitHeadRef()
+			if err := repoObj.Repo.Open(args[0].Inspect()); err != nil {
+				return newError("%s while executing 'initRepo'", err.Error())
+			}
+
+			if err := repoObj.Repo.InitHeadRef(); err != nil {
+				return newError("%s for repository at '%s' while executing 'initRepo'", err.Error(), args[0].Inspect())
+			}
 
 			return repoObj
 		},
 		RequireEnv: true,
 		EnvName:    "repopath",
 	},
+	"fetch": {
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 1 && len(args) != 2 {
+				return newError("wrong number of arguments. got=%d, want=1 or 2", len(args))
+			}
+
+			repo, ok := args[0].(*object.Repo)
+			if !ok {
+				return newError("Unable to convert args[0] to *object.Repo while executing 'fetch'")
+			}
+
+			opts, optsErr := optionsArg("fetch", args, 1)
+			if optsErr != nil {
+				return optsErr
+			}
+
+			fetchOpts := scl.FetchOptions{}
+			var err error
+
+			if fetchOpts.RemoteName, err = optionString(opts, "remote", ""); err != nil {
+				return newError("%s while executing 'fetch'", err.Error())
+			}
+
+			if fetchOpts.RefSpecs, err = optionStrings(opts, "refspecs"); err != nil {
+				return newError("%s while executing 'fetch'", err.Error())
+			}
+
+			if fetchOpts.Tags, err = optionBool(opts, "tags", true); err != nil {
+				return newError("%s while executing 'fetch'", err.Error())
+			}
+
+			if fetchOpts.Prune, err = optionBool(opts, "prune", false); err != nil {
+				return newError("%s while executing 'fetch'", err.Error())
+			}
+
+			if err := repo.Repo.Fetch(fetchOpts); err != nil {
+				return newError("%s while executing 'fetch' on '%s'", err.Error(), repo.Inspect())
+			}
+
+			return NULL
+		},
+	},
 	"whichRepo": {
 		Fn: func(args ...object.Object) object.Object {
 			if len(args) != 1 {
@@ -145,8 +236,8 @@ var builtins = map[string]*object.Builtin{
 	},
 	"extractTags": {
 		Fn: func(args ...object.Object) object.Object {
-			if len(args) != 2 {
-				return newError("wrong number of arguments. got=%d, want=2", len(args))
+			if len(args) != 2 && len(args) != 3 {
+				return newError("wrong number of arguments. got=%d, want=2 or 3", len(args))
 			}
 
 			repo, ok := args[0].(*object.Repo)
@@ -159,27 +250,21 @@ var builtins = map[string]*object.Builtin{
 				return newError("Unable to convert args[1] to *object.String while executing 'extractTags'")
 			}
 
-			var buffer bytes.Buffer
-			for _, c := range version.Inspect() {
-				cc := string(c)
-
-				if cc == "$" {
-					buffer.WriteString("([0-9]+)")
-				} else if cc == "." {
-					buffer.WriteString("\\.")
-				} else if cc == "*" {
-					buffer.WriteString(".*")
-				} else if cc == "+" {
-					buffer.WriteString(".+")
-				} else {
-					buffer.WriteString(cc)
+			order := scl.OrderByDate
+			if len(args) == 3 {
+				orderName, ok := args[2].(*object.String)
+				if !ok {
+					return newError("Unable to convert args[2] to *object.String while executing 'extractTags'")
 				}
-			}
 
-			buffer.WriteString("$")
+				var err error
+				if order, err = scl.ParseTagOrder(orderName.Value); err != nil {
+					return newError("%s while executing 'extractTags'", err.Error())
+				}
+			}
 
-			if ok := repo.Repo.FetchAllMatchingTags(buffer.String()); !ok {
-				return newError("Failed to fetch tag (plumbing.Reference) on repo")
+			if err := repo.Repo.FetchAllMatchingTags(scl.TagFormatRegex(version.Value), order); err != nil {
+				return newError("%s while executing 'extractTags'", err.Error())
 			}
 
 			return NULL
@@ -201,7 +286,11 @@ var builtins = map[string]*object.Builtin{
 				return newError("Unable to convert args[1] to *object.String while executing 'getTag'")
 			}
 
-			tag := repo.Repo.GetSpecificTag(tagName.Value)
+			tag, err := repo.Repo.GetSpecificTag(tagName.Value)
+			if err != nil {
+				return newError("%s while executing 'getTag'", err.Error())
+			}
+
 			if tag == nil {
 				return NULL
 			}
@@ -237,131 +326,497 @@ var builtins = map[string]*object.Builtin{
 			return pr
 		},
 	},
-	"diff": {
+	"getRevision": {
 		Fn: func(args ...object.Object) object.Object {
-			if len(args) != 4 {
-				return newError("wrong number of arguments. got=%d, want=3", len(args)-1)
+			if len(args) != 2 {
+				return newError("wrong number of arguments. got=%d, want=2", len(args))
 			}
 
-			ticketRegex, ok := args[0].(*object.String)
+			repo, ok := args[0].(*object.Repo)
 			if !ok {
-				return newError("Unable to convert args[0] to *object.String while executing 'diff'")
+				return newError("Unable to convert args[0] to *object.Repo while executing 'getRevision'")
 			}
 
-			repo, ok := args[1].(*object.Repo)
+			revision, ok := args[1].(*object.String)
 			if !ok {
-				return newError("Unable to convert args[1] to *object.Repo while executing 'diff'")
+				return newError("Unable to convert args[1] to *object.String while executing 'getRevision'")
 			}
 
-			from, ok := args[2].(*object.Tag)
-			if !ok {
-				return newError("Unable to convert args[2] ('from') to *object.Tag while executing 'diff'")
+			commit, err := repo.Repo.ResolveRevision(revision.Value)
+			if err != nil {
+				return newError("%s while executing 'getRevision'", err.Error())
 			}
 
-			to, ok := args[3].(*object.Tag)
+			return &object.Revision{Value: revision, Commit: commit}
+		},
+	},
+	"commits": {
+		Fn: func(args ...object.Object) object.Object {
+			ra, err := parseRangeArgs("commits", args, 0)
+			if err != nil {
+				return err
+			}
+
+			selection, err := ra.selection(false)
+			if err != nil {
+				return err
+			}
+
+			elements := make([]object.Object, 0, len(selection.commits))
+			for _, c := range selection.commits {
+				elements = append(elements, &object.Commit{Commit: c})
+			}
+
+			return &object.Array{Elements: elements}
+		},
+	},
+	"diff": {
+		Fn: func(args ...object.Object) object.Object {
+			return diff("diff", "", args)
+		},
+		RequireEnv: true,
+		EnvName:    "tickets",
+	},
+	// compare is diff with the 'sides' option set to 'both' by default
+	"compare": {
+		Fn: func(args ...object.Object) object.Object {
+			return diff("compare", "both", args)
+		},
+		RequireEnv: true,
+		EnvName:    "tickets",
+	},
+	"reverted": {
+		Fn: func(args ...object.Object) object.Object {
+			ticketRegex, ra, err := parseTicketRangeArgs("reverted", args)
+			if err != nil {
+				return err
+			}
+
+			selection, err := ra.selection(false)
+			if err != nil {
+				return err
+			}
+
+			scanner, err := ra.scanner(ticketRegex)
+			if err != nil {
+				return err
+			}
+
+			ticketSlice, scanErr := scanner.scanApart(selection.reverted, selection.commits)
+			if scanErr != nil {
+				return newError("%s while executing 'reverted'", scanErr.Error())
+			}
+
+			return stringArray(ticketSlice)
+		},
+		RequireEnv: true,
+		EnvName:    "tickets",
+	},
+	"backported": {
+		Fn: func(args ...object.Object) object.Object {
+			ticketRegex, ra, err := parseTicketRangeArgs("backported", args)
+			if err != nil {
+				return err
+			}
+
+			selection, err := ra.selection(true)
+			if err != nil {
+				return err
+			}
+
+			scanner, err := ra.scanner(ticketRegex)
+			if err != nil {
+				return err
+			}
+
+			ticketSlice, scanErr := scanner.scanApart(selection.backported, selection.commits)
+			if scanErr != nil {
+				return newError("%s while executing 'backported'", scanErr.Error())
+			}
+
+			return stringArray(ticketSlice)
+		},
+		RequireEnv: true,
+		EnvName:    "tickets",
+	},
+	"ticketSources": {
+		Fn: func(args ...object.Object) object.Object {
+			ticketRegex, ra, err := parseTicketRangeArgs("ticketSources", args)
+			if err != nil {
+				return err
+			}
+
+			selection, err := ra.selection(false)
+			if err != nil {
+				return err
+			}
+
+			scanner, err := ra.scanner(ticketRegex)
+			if err != nil {
+				return err
+			}
+
+			result, scanErr := scanner.scanIssues(selection.commits)
+			if scanErr != nil {
+				return newError("%s while executing 'ticketSources'", scanErr.Error())
+			}
+
+			pairs := make(map[string]object.Object, len(result.sources))
+			for ticket, ticketSources := range result.sources {
+				pairs[ticket] = stringArray(ticketSources)
+			}
+
+			return object.NewStringHash(pairs)
+		},
+		RequireEnv: true,
+		EnvName:    "tickets",
+	},
+	"issues": {
+		Fn: func(args ...object.Object) object.Object {
+			ticketRegex, ra, err := parseTicketRangeArgs("issues", args)
+			if err != nil {
+				return err
+			}
+
+			selection, err := ra.selection(false)
+			if err != nil {
+				return err
+			}
+
+			scanner, err := ra.scanner(ticketRegex)
+			if err != nil {
+				return err
+			}
+
+			result, scanErr := scanner.scanIssues(selection.commits)
+			if scanErr != nil {
+				return newError("%s while executing 'issues'", scanErr.Error())
+			}
+
+			elements := make([]object.Object, 0, len(result.issues))
+			for _, found := range result.issues {
+				elements = append(elements, object.NewStringHash(map[string]object.Object{
+					"key":     &object.String{Value: found.Key},
+					"tracker": &object.String{Value: found.Tracker},
+					"sources": stringArray(result.sources[found.Key]),
+				}))
+			}
+
+			return &object.Array{Elements: elements}
+		},
+		RequireEnv: true,
+		EnvName:    "tickets",
+	},
+	"render": {
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 1 && len(args) != 2 {
+				return newError("wrong number of arguments. got=%d, want=1 or 2", len(args))
+			}
+
+			// The default release notes template is used when none is specified: render(data)
+			text := ""
+			if len(args) == 2 {
+				tmpl, ok := args[0].(*object.String)
+				if !ok {
+					return newError("Unable to convert args[0] to *object.String while executing 'render'")
+				}
+
+				text = tmpl.Value
+			}
+
+			var out bytes.Buffer
+			if err := output.Markdown(&out, args[len(args)-1], text); err != nil {
+				return newError("%s while executing 'render'", err.Error())
+			}
+
+			return &object.String{Value: out.String()}
+		},
+	},
+	"updateChangelog": {
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 2 && len(args) != 3 {
+				return newError("wrong number of arguments. got=%d, want=2 or 3", len(args))
+			}
+
+			path, ok := args[0].(*object.String)
 			if !ok {
-				return newError("Unable to convert args[3] ('to') to *object.Tag while executing 'diff'")
-			}
-
-			// ---------------------------------------------
-			// Extract the log iterator from the 'from' hash
-			// ---------------------------------------------
-			commitFromTag, _ := from.Tag.Commit()
-			iterFrom, errFrom := repo.Repo.GitRepo.Log(&git.LogOptions{From: commitFromTag.Hash})
-			if errFrom != nil {
-				return newError("an error occured while retrieving the commit history from 'fromHash'")
-			}
-
-			// -------------------------------------------
-			// Extract the log iterator from the 'to' hash
-			// -------------------------------------------
-			commitToTag, _ := to.Tag.Commit()
-			iterTo, errTo := repo.Repo.GitRepo.Log(&git.LogOptions{From: commitToTag.Hash})
-			if errTo != nil {
-				return newError("an error occured while retrieving the commit history from 'toHash'")
-			}
-
-			// TODO: Uncomment the following line if needed when debugging
-			//fmt.Printf("Performing diff on %s --> %s\n", from.Tag.Name, to.Tag.Name)
-
-			// ----------------------------
-			// Initialize the commit slices
-			// ----------------------------
-			commitFromSlice := make([]*gitobject.Commit, 0)
-			commitToSlice := make([]*gitobject.Commit, 0)
-
-			_ = iterFrom.ForEach(func(commit *gitobject.Commit) error {
-				commitFromSlice = append(commitFromSlice, commit)
-				return nil
-			})
-
-			_ = iterTo.ForEach(func(commit *gitobject.Commit) error {
-				commitToSlice = append(commitToSlice, commit)
-				return nil
-			})
-
-			// ------------------------------------------------
-			// Perform the actual diff operation on both slices
-			// ------------------------------------------------
-			diff := make([]*gitobject.Commit, 0)
-			for i := 0; i < 2; i++ {
-				for _, s1 := range commitToSlice {
-					found := false
-					for _, s2 := range commitFromSlice {
-						if s1.Hash == s2.Hash {
-							found = true
-							break
-						}
-					}
-
-					if !found {
-						diff = append(diff, s1)
-					}
+				return newError("Unable to convert args[0] to *object.String while executing 'updateChangelog'")
+			}
+
+			// The version is the name of the tag ending the range when it isn't specified
+			version := ""
+			if len(args) == 3 {
+				versionArg, ok := args[2].(*object.String)
+				if !ok {
+					return newError("Unable to convert args[2] to *object.String while executing 'updateChangelog'")
 				}
 
-				if i == 0 {
-					commitToSlice, commitFromSlice = commitFromSlice, commitToSlice
+				version = versionArg.Value
+			}
+
+			updated, err := changelog.UpdateFromResult(path.Value, args[1], version)
+			if err != nil {
+				return newError("%s while executing 'updateChangelog'", err.Error())
+			}
+
+			return nativeBoolToBooleanObject(updated)
+		},
+	},
+	"notes": {
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 2 && len(args) != 3 {
+				return newError("wrong number of arguments. got=%d, want=2 or 3", len(args))
+			}
+
+			repo, ok := args[0].(*object.Repo)
+			if !ok {
+				return newError("Unable to convert args[0] to *object.Repo while executing 'notes'")
+			}
+
+			commit, ok := args[1].(object.Committish)
+			if !ok {
+				return newError("Unable to convert args[1] to a TAG, a REVISION or a COMMIT while executing 'notes'")
+			}
+
+			ref := scl.DefaultNotesRef
+			if len(args) == 3 {
+				refName, ok := args[2].(*object.String)
+				if !ok {
+					return newError("Unable to convert args[2] to *object.String while executing 'notes'")
 				}
+				ref = refName.Value
+			}
+
+			notes, err := repo.Repo.Notes(ref)
+			if err != nil {
+				return newError("%s while executing 'notes'", err.Error())
+			}
+
+			note, found, err := notes.Note(commit.ResolvedCommit().Hash)
+			if err != nil {
+				return newError("%s while executing 'notes'", err.Error())
+			}
+
+			if !found {
+				return NULL
+			}
+
+			return &object.String{Value: note}
+		},
+	},
+	"addNote": {
+		Fn: func(args ...object.Object) object.Object {
+			ticketRegex, ra, err := parseTicketRangeArgs("addNote", args)
+			if err != nil {
+				return err
+			}
+
+			ticketSlice, err := ra.tickets(ticketRegex)
+			if err != nil {
+				return err
+			}
+
+			ref, optErr := optionString(ra.opts, "writeNotesRef", scl.DefaultNotesRef)
+			if optErr != nil {
+				return ra.optionError(optErr)
+			}
+
+			force, optErr := optionBool(ra.opts, "force", false)
+			if optErr != nil {
+				return ra.optionError(optErr)
+			}
+
+			// The 'tagger' option (the identity given to 'createTag') is used when there is no 'author' option
+			authorKey := "author"
+			if _, ok := optionValue(ra.opts, authorKey); !ok {
+				authorKey = "tagger"
+			}
+
+			author, optErr := optionSignature(ra.opts, authorKey)
+			if optErr != nil {
+				return ra.optionError(optErr)
 			}
 
-			// ------------------------------------------------
-			// Look in the commits and append when matching
-			// ------------------------------------------------
-			var TicketSlice []string
-			for _, c := range diff {
-				if presentInMessage, ticket := tickets(c.Message, ticketRegex.Value); presentInMessage {
-					TicketSlice = append(TicketSlice, ticket...)
+			if author.Name == "" || author.Email == "" {
+				return newError("the 'author' option (name and email) is required while executing 'addNote'")
+			}
+
+			// Git does not keep empty notes
+			if len(ticketSlice) > 0 {
+				note := strings.Join(ticketSlice, "\n")
+				if addErr := ra.repo.Repo.AddNote(ref, ra.to.ResolvedCommit().Hash, note, author, force); addErr != nil {
+					return newError("%s while executing 'addNote'", addErr.Error())
 				}
 			}
 
-			TicketSlice = unique(TicketSlice)
-			fmt.Println(TicketSlice)
+			return stringArray(ticketSlice)
+		},
+		RequireEnv: true,
+		EnvName:    "tickets",
+	},
+	"createTag": {
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 5 && len(args) != 6 {
+				return newError("wrong number of arguments. got=%d, want=4 or 5", len(args)-1)
+			}
 
-			return NULL
+			tagName, ok := args[4].(*object.String)
+			if !ok {
+				return newError("Unable to convert args[4] to *object.String while executing 'createTag'")
+			}
+
+			// The tag name is between the range and the options: (tickets, repo, from, to, name[, opts])
+			withoutName := append(append([]object.Object{}, args[:4]...), args[5:]...)
+			ticketRegex, ra, err := parseTicketRangeArgs("createTag", withoutName)
+			if err != nil {
+				return err
+			}
+
+			ticketSlice, err := ra.tickets(ticketRegex)
+			if err != nil {
+				return err
+			}
+
+			title, optErr := optionString(ra.opts, "message", "Release "+tagName.Value)
+			if optErr != nil {
+				return ra.optionError(optErr)
+			}
+
+			tagger, optErr := optionSignature(ra.opts, "tagger")
+			if optErr != nil {
+				return ra.optionError(optErr)
+			}
+
+			message := title + "\n"
+			if len(ticketSlice) > 0 {
+				message += "\n" + strings.Join(ticketSlice, "\n") + "\n"
+			}
+
+			tag, tagErr := ra.repo.Repo.CreateTag(tagName.Value, ra.to.ResolvedCommit().Hash, message, tagger)
+			if tagErr != nil {
+				return newError("%s while executing 'createTag'", tagErr.Error())
+			}
+
+			return &object.Tag{Value: &object.String{Value: tag.Name}, Tag: tag}
 		},
 		RequireEnv: true,
 		EnvName:    "tickets",
 	},
+	"trailers": {
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newError("wrong number of arguments. got=%d, want=1", len(args))
+			}
+
+			commit, ok := args[0].(object.Committish)
+			if !ok {
+				return newError("Unable to convert args[0] to a TAG, a REVISION or a COMMIT while executing 'trailers'")
+			}
+
+			// The keys are case insensitive, the values of a key are grouped under its first spelling
+			keys := make(map[string]string)
+			values := make(map[string][]string)
+
+			for _, trailer := range scl.ParseTrailers(commit.ResolvedCommit().Message) {
+				key, seen := keys[strings.ToLower(trailer.Key)]
+				if !seen {
+					key = trailer.Key
+					keys[strings.ToLower(key)] = key
+				}
+				values[key] = append(values[key], trailer.Value)
+			}
+
+			pairs := make(map[string]object.Object, len(values))
+			for key, keyValues := range values {
+				pairs[key] = stringArray(keyValues)
+			}
+
+			return object.NewStringHash(pairs)
+		},
+	},
 }
 
-func tickets(text, ticketRegex string) (bool, []string) {
-	regex := "((?:"
-	if ticketRegex == "*" {
-		regex += "[a-zA-Z0-9]+"
-	} else {
-		regex += strings.ReplaceAll(ticketRegex, ",", "|")
+// diff returns the tickets of the range, its details or, when the 'sides' option (defaultSides when absent) is set,
+// the tickets new in 'to' and the ones only in 'from'
+func diff(builtin string, defaultSides string, args []object.Object) object.Object {
+	ticketRegex, ra, err := parseTicketRangeArgs(builtin, args)
+	if err != nil {
+		return err
+	}
+
+	sides, optErr := optionString(ra.opts, "sides", defaultSides)
+	if optErr != nil {
+		return ra.optionError(optErr)
+	}
+
+	withCommits, optErr := optionBool(ra.opts, "commits", false)
+	if optErr != nil {
+		return ra.optionError(optErr)
+	}
+
+	details, optErr := optionBool(ra.opts, "details", withCommits)
+	if optErr != nil {
+		return ra.optionError(optErr)
+	}
+
+	switch sides {
+	case "":
+		if details {
+			hash, err := ra.details(ticketRegex, withCommits)
+			if err != nil {
+				return err
+			}
+
+			return hash
+		}
+
+		ticketSlice, err := ra.tickets(ticketRegex)
+		if err != nil {
+			return err
+		}
+
+		return stringArray(ticketSlice)
+	case "added", "removed", "both":
+	default:
+		return newError("unknown sides '%s' (expected 'added', 'removed' or 'both') while executing '%s'", sides, builtin)
+	}
+
+	added, removed, err := ra.compare(ticketRegex)
+	if err != nil {
+		return err
+	}
+
+	pairs := map[string]object.Object{"from": ra.from, "to": ra.to}
+	if details {
+		hash, err := ra.details(ticketRegex, withCommits)
+		if err != nil {
+			return err
+		}
+
+		for _, pair := range hash.Pairs {
+			pairs[pair.Key.Inspect()] = pair.Value
+		}
+	}
+
+	if sides != "removed" {
+		pairs["added"] = stringArray(added)
 	}
-	regex += ")-[0-9]+)"
 
-	r, _ := regexp.Compile(regex)
+	if sides != "added" {
+		pairs["removed"] = stringArray(removed)
+	}
 
-	out := r.FindAllString(text, -1)
+	return object.NewStringHash(pairs)
+}
 
-	if len(out) == 0 {
-		return false, []string{}
+// stringArray converts the values to an array of strings
+func stringArray(values []string) *object.Array {
+	elements := make([]object.Object, 0, len(values))
+	for _, value := range values {
+		elements = append(elements, &object.String{Value: value})
 	}
 
-	return true, out
+	return &object.Array{Elements: elements}
 }
 
 func unique(s []string) []string {
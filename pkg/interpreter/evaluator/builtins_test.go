@@ -0,0 +1,1021 @@
+package evaluator
+
+import (
+	"fmt"
+	"github.com/TurnsCoffeeIntoScripts/git-log-issue-finder/pkg/internal/testrepo"
+	"github.com/TurnsCoffeeIntoScripts/git-log-issue-finder/pkg/interpreter/object"
+	"github.com/TurnsCoffeeIntoScripts/git-log-issue-finder/pkg/issue"
+	"github.com/go-git/go-git/v5/plumbing"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// scriptRepo is a repository of the tests (see testrepo.Repository) on which the builtins can be evaluated
+type scriptRepo struct {
+	*testrepo.Repository
+}
+
+func newScriptRepo(t *testing.T) *scriptRepo {
+	return &scriptRepo{Repository: testrepo.New(t)}
+}
+
+// eval evaluates the script after opening the repository in the 'repo' variable
+func (sr *scriptRepo) eval(script string) object.Object {
+	return testEval(fmt.Sprintf("set repopath %q; let repo = initRepo(); %s", sr.Dir, script))
+}
+
+// anyJiraMatcher returns the matcher of the Jira keys of every project
+func anyJiraMatcher(t *testing.T) *issue.Matcher {
+	m, err := issue.NewMatcher(issue.AnyProject, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("NewMatcher returned an error: %v", err)
+	}
+
+	return m
+}
+
+func TestCommitsBuiltin(t *testing.T) {
+	sr := newScriptRepo(t)
+	defer sr.Cleanup()
+
+	first := sr.Commit("ABC-1 first")
+	sr.AnnotatedTag("1.0.0", first)
+	second := sr.Commit("ABC-2 second\n\nLonger description\nof the change")
+	third := sr.Commit("ABC-3 third\ncontinued")
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`let c = commits(repo, getTag(repo, "1.0.0"), getRevision(repo, "HEAD")); len(c);`, "2"},
+		{`let c = commits(repo, getLatestTag(repo, 0), getRevision(repo, "HEAD")); len(c);`, "3"},
+		{`let c = commits(repo, getTag(repo, "1.0.0"), getRevision(repo, "HEAD")); c[0]["hash"];`, third.String()},
+		{`let c = commits(repo, getTag(repo, "1.0.0"), getRevision(repo, "HEAD")); c[0]["subject"];`, "ABC-3 third continued"},
+		{`let c = commits(repo, getTag(repo, "1.0.0"), getRevision(repo, "HEAD")); c[1]["shortHash"];`, second.String()[:7]},
+		{`let c = commits(repo, getTag(repo, "1.0.0"), getRevision(repo, "HEAD")); c[1]["body"];`, "Longer description\nof the change"},
+		{`let c = commits(repo, getTag(repo, "1.0.0"), getRevision(repo, "HEAD")); c[1]["author"];`, "glif"},
+		{`let c = commits(repo, getTag(repo, "1.0.0"), getRevision(repo, "HEAD")); c[1]["authorDate"];`, "2020-01-01T12:03:00Z"},
+		{`let c = commits(repo, getTag(repo, "1.0.0"), getRevision(repo, "HEAD")); c[1]["parents"][0];`, first.String()},
+	}
+
+	for _, tt := range tests {
+		evaluated := sr.eval(tt.input)
+
+		switch result := evaluated.(type) {
+		case *object.Integer:
+			if fmt.Sprint(result.Value) != tt.expected {
+				t.Errorf("%s: wrong value. got=%d, want=%s", tt.input, result.Value, tt.expected)
+			}
+		case *object.String:
+			if result.Value != tt.expected {
+				t.Errorf("%s: wrong value. got=%q, want=%q", tt.input, result.Value, tt.expected)
+			}
+		default:
+			t.Errorf("%s: unexpected result. got=%T(%+v)", tt.input, evaluated, evaluated)
+		}
+	}
+}
+
+func TestCommitsBuiltinErrors(t *testing.T) {
+	sr := newScriptRepo(t)
+	defer sr.Cleanup()
+
+	sr.Commit("ABC-1 first")
+
+	tests := []struct {
+		input           string
+		expectedMessage string
+	}{
+		{`commits(repo, getLatestTag(repo, 0));`, "wrong number of arguments. got=2, want=3 or 4"},
+		{`commits(repo, "1.0.0", getRevision(repo, "HEAD"));`,
+			"Unable to convert args[1] ('from') to a TAG, a REVISION or a COMMIT while executing 'commits'"},
+		{`commits(repo, getLatestTag(repo, 0), getRevision(repo, "HEAD"), {"mode": "other"});`,
+			"unknown diff mode 'other' (expected 'range' or 'symmetric') while executing 'commits'"},
+		{`let c = commits(repo, getLatestTag(repo, 0), getRevision(repo, "HEAD")); c[0]["unknown"];`, "unknown field of COMMIT: unknown"},
+	}
+
+	for _, tt := range tests {
+		evaluated := sr.eval(tt.input)
+
+		errObj, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("%s: no error object returned. got=%T(%+v)", tt.input, evaluated, evaluated)
+			continue
+		}
+
+		if errObj.Message != tt.expectedMessage {
+			t.Errorf("wrong error message. expected=%q, got=%q", tt.expectedMessage, errObj.Message)
+		}
+	}
+}
+
+func TestCommitsBuiltinPathOptions(t *testing.T) {
+	sr := newScriptRepo(t)
+	defer sr.Cleanup()
+
+	sr.Commit("ABC-1 service a", "services/service-a/main.go")
+	sr.Commit("ABC-2 service b", "services/service-b/main.go")
+	sr.Commit("ABC-3 docs of a", "services/service-a/README.md")
+
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{`len(commits(repo, getLatestTag(repo, 0), getRevision(repo, "HEAD"), {"include": "services/service-a/**"}));`, 2},
+		{`len(commits(repo, getLatestTag(repo, 0), getRevision(repo, "HEAD"), {"include": ["services/service-a/**"], "exclude": ["**/*.md"]}));`, 1},
+		{`len(commits(repo, getLatestTag(repo, 0), getRevision(repo, "HEAD"), {"exclude": "services/service-b/**, **/*.md"}));`, 1},
+	}
+
+	for _, tt := range tests {
+		testIntegerObject(t, sr.eval(tt.input), tt.expected)
+	}
+
+	evaluated := sr.eval(`commits(repo, getLatestTag(repo, 0), getRevision(repo, "HEAD"), {"include": "[a-"});`)
+	expected := "invalid path pattern '[a-': syntax error in pattern while executing 'commits'"
+	if errObj, ok := evaluated.(*object.Error); !ok || errObj.Message != expected {
+		t.Errorf("wrong result for an invalid pattern. got=%+v, want=%q", evaluated, expected)
+	}
+}
+
+func TestRevertedBuiltin(t *testing.T) {
+	sr := newScriptRepo(t)
+	defer sr.Cleanup()
+
+	sr.AnnotatedTag("1.0.0", sr.Commit("ABC-1 first"))
+	feature := sr.Commit("ABC-2 feature")
+	sr.Commit("ABC-3 other")
+	sr.Commit("Revert \"ABC-2 feature\"\n\nThis reverts commit " + feature.String() + ".")
+	sr.Commit("XYZ-4 fix")
+	sr.Commit("Revert \"XYZ-4 fix\"")
+	sr.Commit("XYZ-4 fix again")
+
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{`set tickets "*"; reverted(repo, getTag(repo, "1.0.0"), getRevision(repo, "HEAD"));`, []string{"ABC-2"}},
+		{`set tickets "XYZ"; reverted(repo, getTag(repo, "1.0.0"), getRevision(repo, "HEAD"));`, []string{}},
+		{`set tickets "*"; reverted(repo, getTag(repo, "1.0.0"), getRevision(repo, "HEAD"), {"cancelReverts": false});`, []string{}},
+		{`let c = commits(repo, getTag(repo, "1.0.0"), getRevision(repo, "HEAD")); [c[0]["subject"], c[1]["subject"]];`,
+			[]string{"XYZ-4 fix again", "ABC-3 other"}},
+	}
+
+	for _, tt := range tests {
+		evaluated := sr.eval(tt.input)
+
+		result, ok := evaluated.(*object.Array)
+		if !ok {
+			t.Errorf("%s: object is not Array. got=%T(%+v)", tt.input, evaluated, evaluated)
+			continue
+		}
+
+		if len(result.Elements) != len(tt.expected) {
+			t.Errorf("%s: wrong number of elements. got=%d, want=%d", tt.input, len(result.Elements), len(tt.expected))
+			continue
+		}
+
+		for i, expected := range tt.expected {
+			testStringObject(t, result.Elements[i], expected)
+		}
+	}
+}
+
+func TestBackportedBuiltin(t *testing.T) {
+	sr := newScriptRepo(t)
+	defer sr.Cleanup()
+
+	base := sr.Commit("ABC-1 base")
+	sr.AnnotatedTag("1.0.0", base)
+	sr.Commit("ABC-2 hotfix", "fix.txt")
+	sr.Commit("ABC-3 feature", "feature.txt")
+	sr.Checkout("release", base)
+	sr.Commit("ABC-2 hotfix", "fix.txt")
+	sr.AnnotatedTag("1.0.1", sr.Commit("XYZ-4 release only", "release.txt"))
+
+	script := `set tickets "*"; let from = getTag(repo, "1.0.1"); let to = getRevision(repo, "master"); `
+
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{`backported(repo, from, to);`, []string{"ABC-2"}},
+		{`let c = commits(repo, from, to); [c[0]["subject"], c[1]["subject"]];`, []string{"ABC-3 feature", "ABC-2 hotfix"}},
+		{`let c = commits(repo, from, to, {"patchId": true}); [c[0]["subject"]];`, []string{"ABC-3 feature"}},
+		{`let c = commits(repo, from, to, {"patchId": true, "mode": "symmetric"}); [c[0]["subject"], c[1]["subject"]];`,
+			[]string{"ABC-3 feature", "XYZ-4 release only"}},
+	}
+
+	for _, tt := range tests {
+		evaluated := sr.eval(script + tt.input)
+
+		result, ok := evaluated.(*object.Array)
+		if !ok {
+			t.Errorf("%s: object is not Array. got=%T(%+v)", tt.input, evaluated, evaluated)
+			continue
+		}
+
+		if len(result.Elements) != len(tt.expected) {
+			t.Errorf("%s: wrong number of elements. got=%d, want=%d", tt.input, len(result.Elements), len(tt.expected))
+			continue
+		}
+
+		for i, expected := range tt.expected {
+			testStringObject(t, result.Elements[i], expected)
+		}
+	}
+
+	evaluated := sr.eval(script + `len(commits(repo, from, to, {"patchId": true, "mode": "symmetric"}));`)
+	testIntegerObject(t, evaluated, 2)
+}
+
+func TestNotesBuiltin(t *testing.T) {
+	sr := newScriptRepo(t)
+	defer sr.Cleanup()
+
+	first := sr.Commit("ABC-1 first")
+	second := sr.Commit("second without ticket")
+	sr.Git("notes", "add", "-m", "ABC-2", second.String())
+	sr.Git("notes", "--ref", "jira", "add", "-m", "XYZ-3", first.String())
+
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`notes(repo, getRevision(repo, "HEAD"));`, "ABC-2\n"},
+		{`notes(repo, getRevision(repo, "HEAD~1"));`, nil},
+		{`notes(repo, getRevision(repo, "HEAD~1"), "jira");`, "XYZ-3\n"},
+		{`notes(repo, commits(repo, getLatestTag(repo, 0), getRevision(repo, "HEAD"))[1], "refs/notes/jira");`, "XYZ-3\n"},
+	}
+
+	for _, tt := range tests {
+		evaluated := sr.eval(tt.input)
+		if expected, ok := tt.expected.(string); ok {
+			testStringObject(t, evaluated, expected)
+		} else {
+			testNullObject(t, evaluated)
+		}
+	}
+
+	glifRepo := sr.eval("repo;").(*object.Repo).Repo
+	commitRange, err := glifRepo.WalkRange(plumbing.ZeroHash, second)
+	if err != nil {
+		t.Fatalf("WalkRange returned an error: %v", err)
+	}
+
+	scanTests := []struct {
+		notesRef string
+		expected []string
+	}{
+		{"", []string{"ABC-2", "ABC-1"}},
+		{"jira", []string{"ABC-1", "XYZ-3"}},
+	}
+
+	for _, tt := range scanTests {
+		notes, err := glifRepo.Notes(tt.notesRef)
+		if err != nil {
+			t.Fatalf("Notes returned an error: %v", err)
+		}
+
+		scanner := &ticketScanner{matcher: anyJiraMatcher(t), notes: notes}
+		got, err := scanner.scan(commitRange.ToOnly)
+		if err != nil {
+			t.Fatalf("scan returned an error: %v", err)
+		}
+
+		if fmt.Sprint(got) != fmt.Sprint(tt.expected) {
+			t.Errorf("wrong tickets with notes '%s'. got=%v, want=%v", tt.notesRef, got, tt.expected)
+		}
+	}
+}
+
+func TestAddNoteBuiltin(t *testing.T) {
+	sr := newScriptRepo(t)
+	defer sr.Cleanup()
+
+	sr.AnnotatedTag("1.0.0", sr.Commit("ABC-1 first"))
+	sr.Commit("ABC-2 second")
+	head := sr.Commit("XYZ-3 third")
+
+	script := `set tickets "*"; let from = getTag(repo, "1.0.0"); let to = getRevision(repo, "HEAD");
+let tagger = {"name": "Release Bot", "email": "release@example.com"}; `
+
+	evaluated := sr.eval(script + `addNote(repo, from, to, {"author": tagger});`)
+	if result, ok := evaluated.(*object.Array); !ok || len(result.Elements) != 2 {
+		t.Fatalf("addNote should return the tickets. got=%T(%+v)", evaluated, evaluated)
+	}
+
+	if got := sr.Git("notes", "show", head.String()); got != "XYZ-3\nABC-2\n" {
+		t.Errorf("wrong note. got=%q", got)
+	}
+
+	if got := sr.Git("log", "-1", "--format=%an <%ae>", "refs/notes/commits"); got != "Release Bot <release@example.com>\n" {
+		t.Errorf("wrong author of the notes commit. got=%q", got)
+	}
+
+	// Same result: nothing to do (the identity of 'createTag' is accepted as well)
+	evaluated = sr.eval(script + `addNote(repo, from, to, {"tagger": tagger});`)
+	if _, ok := evaluated.(*object.Array); !ok {
+		t.Errorf("addNote of the same tickets should succeed. got=%T(%+v)", evaluated, evaluated)
+	}
+
+	// The note written on 'to' is scanned as well unless the notes are ignored
+	evaluated = sr.eval(script + `addNote(repo, getRevision(repo, "HEAD~1"), to, {"author": tagger, "notes": false});`)
+	expected := "commit " + head.String() + " already has a note in 'refs/notes/commits' while executing 'addNote'"
+	if errObj, ok := evaluated.(*object.Error); !ok || errObj.Message != expected {
+		t.Errorf("addNote should fail when a different note exists. got=%+v, want=%q", evaluated, expected)
+	}
+
+	sr.eval(script + `addNote(repo, getRevision(repo, "HEAD~1"), to, {"author": tagger, "notes": false, "writeNotesRef": "release"});`)
+	if got := sr.Git("notes", "--ref", "release", "show", head.String()); got != "XYZ-3\n" {
+		t.Errorf("wrong note in refs/notes/release. got=%q", got)
+	}
+
+	evaluated = sr.eval(script + `addNote(repo, from, to);`)
+	expected = "the 'author' option (name and email) is required while executing 'addNote'"
+	if errObj, ok := evaluated.(*object.Error); !ok || errObj.Message != expected {
+		t.Errorf("addNote should fail without author. got=%+v, want=%q", evaluated, expected)
+	}
+
+	// The author takes precedence over the tagger
+	sr.eval(script + `addNote(repo, from, to, {"author": {"name": "Notes Bot", "email": "notes@example.com"},
+"tagger": tagger, "writeNotesRef": "author", "notes": false});`)
+	if got := sr.Git("log", "-1", "--format=%an <%ae>", "refs/notes/author"); got != "Notes Bot <notes@example.com>\n" {
+		t.Errorf("wrong author of the notes commit with both options. got=%q", got)
+	}
+}
+
+func TestCreateTagBuiltin(t *testing.T) {
+	sr := newScriptRepo(t)
+	defer sr.Cleanup()
+
+	sr.AnnotatedTag("1.0.0", sr.Commit("ABC-1 first"))
+	sr.Commit("ABC-2 second")
+	head := sr.Commit("XYZ-3 third")
+
+	script := `set tickets "*"; let from = getTag(repo, "1.0.0"); let to = getRevision(repo, "HEAD");
+let tagger = {"name": "Release Bot", "email": "release@example.com"}; `
+
+	evaluated := sr.eval(script + `let tag = createTag(repo, from, to, "1.1.0", {"tagger": tagger}); len(commits(repo, tag, to));`)
+	testIntegerObject(t, evaluated, 0)
+
+	out := sr.Git("cat-file", "-p", "refs/tags/1.1.0")
+	for _, expected := range []string{"object " + head.String(), "tagger Release Bot <release@example.com>",
+		"\nRelease 1.1.0\n\nXYZ-3\nABC-2\n"} {
+		if !strings.Contains(out, expected) {
+			t.Errorf("the tag does not contain %q. got=%q", expected, out)
+		}
+	}
+
+	sr.eval(script + `createTag(repo, from, to, "1.1.1", {"tagger": tagger, "message": "Hotfix", "include": "none/**"});`)
+	if out := sr.Git("cat-file", "-p", "refs/tags/1.1.1"); !strings.HasSuffix(out, "\nHotfix\n") {
+		t.Errorf("wrong message without tickets. got=%q", out)
+	}
+
+	tests := []struct {
+		input           string
+		expectedMessage string
+	}{
+		{`createTag(repo, from, to, "1.1.0", {"tagger": tagger});`,
+			"unable to create the tag '1.1.0': tag already exists while executing 'createTag'"},
+		{`createTag(repo, from, to, "1.2.0");`,
+			"the name and the email of the tagger are required to create the tag '1.2.0' while executing 'createTag'"},
+		{`createTag(repo, from, to, "1.2.0", {"tagger": "Release Bot"});`,
+			"option 'tagger' must be HASH, got STRING while executing 'createTag'"},
+		{`createTag(repo, from, to);`, "wrong number of arguments. got=3, want=4 or 5"},
+	}
+
+	for _, tt := range tests {
+		evaluated := sr.eval(script + tt.input)
+
+		errObj, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("%s: no error object returned. got=%T(%+v)", tt.input, evaluated, evaluated)
+			continue
+		}
+
+		if errObj.Message != tt.expectedMessage {
+			t.Errorf("wrong error message. expected=%q, got=%q", tt.expectedMessage, errObj.Message)
+		}
+	}
+}
+
+func TestTrailersBuiltin(t *testing.T) {
+	sr := newScriptRepo(t)
+	defer sr.Cleanup()
+
+	sr.AnnotatedTag("1.0.0", sr.Commit("ABC-1 first"))
+	sr.Commit("Second\n\nThe body mentions UTF-8 and ABC-99.\n\nRefs: ABC-12\nfixes: XYZ-9\nFixes: XYZ-10\nSigned-off-by: glif\n")
+	sr.Commit("Third ABC-3\n\nJira: ABC-13\n")
+
+	script := `let c = commits(repo, getTag(repo, "1.0.0"), getRevision(repo, "HEAD")); let t = trailers(c[1]); `
+
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{`t["Refs"];`, []string{"ABC-12"}},
+		{`t["fixes"];`, []string{"XYZ-9", "XYZ-10"}},
+		{`t["Signed-off-by"];`, []string{"glif"}},
+		{`trailers(c[0])["Jira"];`, []string{"ABC-13"}},
+	}
+
+	for _, tt := range tests {
+		evaluated := sr.eval(script + tt.input)
+
+		result, ok := evaluated.(*object.Array)
+		if !ok {
+			t.Errorf("%s: object is not Array. got=%T(%+v)", tt.input, evaluated, evaluated)
+			continue
+		}
+
+		if len(result.Elements) != len(tt.expected) {
+			t.Errorf("%s: wrong number of elements. got=%d, want=%d", tt.input, len(result.Elements), len(tt.expected))
+			continue
+		}
+
+		for i, expected := range tt.expected {
+			testStringObject(t, result.Elements[i], expected)
+		}
+	}
+
+	testNullObject(t, sr.eval(script+`trailers(getTag(repo, "1.0.0"))["Refs"];`))
+
+	glifRepo := sr.eval("repo;").(*object.Repo).Repo
+	commitRange, err := glifRepo.WalkRange(plumbing.ZeroHash, glifRepo.HeadRef.Hash())
+	if err != nil {
+		t.Fatalf("WalkRange returned an error: %v", err)
+	}
+
+	scanTests := []struct {
+		trailerKeys []string
+		expected    []string
+	}{
+		{nil, []string{"ABC-3", "ABC-13", "ABC-99", "ABC-12", "XYZ-9", "XYZ-10", "ABC-1"}},
+		{[]string{"Refs", "Fixes", "Jira"}, []string{"ABC-13", "ABC-12", "XYZ-9", "XYZ-10"}},
+		{[]string{"jira"}, []string{"ABC-13"}},
+	}
+
+	for _, tt := range scanTests {
+		scanner := &ticketScanner{matcher: anyJiraMatcher(t), trailerKeys: tt.trailerKeys}
+		got, err := scanner.scan(commitRange.ToOnly)
+		if err != nil {
+			t.Fatalf("scan returned an error: %v", err)
+		}
+
+		if fmt.Sprint(got) != fmt.Sprint(tt.expected) {
+			t.Errorf("wrong tickets with trailers %v. got=%v, want=%v", tt.trailerKeys, got, tt.expected)
+		}
+	}
+}
+
+func TestTicketSourcesBuiltin(t *testing.T) {
+	sr := newScriptRepo(t)
+	defer sr.Cleanup()
+
+	first := sr.Commit("ABC-1 first")
+	sr.AnnotatedTag("1.0.0", first)
+	sr.Checkout("feature/ABC-2-login", first)
+	sr.Commit("Login page", "login.txt")
+	sr.Git("checkout", "-q", "master")
+	sr.Git("merge", "-q", "--no-ff", "feature/ABC-2-login", "-m", "Merge branch 'feature/ABC-2-login'\n\nReviewed in ABC-4")
+	sr.Commit("Merge pull request #42 from org/ABC-5-fix\n\nABC-5 fix the crash")
+
+	script := `set tickets "*"; let from = getTag(repo, "1.0.0"); let to = getRevision(repo, "HEAD"); `
+
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{`ticketSources(repo, from, to)["ABC-2"];`, []string{"branch"}},
+		{`ticketSources(repo, from, to)["ABC-4"];`, []string{"message"}},
+		{`ticketSources(repo, from, to)["ABC-5"];`, []string{"message", "branch"}},
+		{`ticketSources(repo, from, to, {"branches": false})["ABC-2"];`, []string{"message"}},
+		{`ticketSources(repo, from, to, {"trailers": ["Refs"]})["ABC-2"];`, []string{"branch"}},
+	}
+
+	for _, tt := range tests {
+		evaluated := sr.eval(script + tt.input)
+
+		result, ok := evaluated.(*object.Array)
+		if !ok {
+			t.Errorf("%s: object is not Array. got=%T(%+v)", tt.input, evaluated, evaluated)
+			continue
+		}
+
+		if len(result.Elements) != len(tt.expected) {
+			t.Errorf("%s: wrong number of elements. got=%d, want=%d", tt.input, len(result.Elements), len(tt.expected))
+			continue
+		}
+
+		for i, expected := range tt.expected {
+			testStringObject(t, result.Elements[i], expected)
+		}
+	}
+
+	testNullObject(t, sr.eval(script+`ticketSources(repo, from, to)["ABC-1"];`))
+	testNullObject(t, sr.eval(script+`ticketSources(repo, from, to, {"trailers": ["Refs"]})["ABC-4"];`))
+
+	errorTests := []struct {
+		input    string
+		expected string
+	}{
+		{`ticketSources(repo, from);`, "wrong number of arguments. got=2, want=3 or 4"},
+		{`ticketSources(repo, from, to, {"branches": "yes"});`,
+			"option 'branches' must be BOOLEAN, got STRING while executing 'ticketSources'"},
+	}
+
+	for _, tt := range errorTests {
+		evaluated := sr.eval(script + tt.input)
+
+		errObj, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("%s: no error object returned. got=%T(%+v)", tt.input, evaluated, evaluated)
+			continue
+		}
+
+		if errObj.Message != tt.expected {
+			t.Errorf("%s: wrong error message. got=%q, want=%q", tt.input, errObj.Message, tt.expected)
+		}
+	}
+}
+
+func TestIssuesBuiltin(t *testing.T) {
+	sr := newScriptRepo(t)
+	defer sr.Cleanup()
+
+	sr.AnnotatedTag("1.0.0", sr.Commit("ABC-1 first"))
+	sr.Commit("ABC-2 fixes #12 and org/repo#34")
+	sr.Commit("Merge request !45 for AB#678 (ABC-2)")
+	sr.Commit("Merge pull request #56 from org/ABC-3-fix")
+
+	script := `set tickets "*"; let from = getTag(repo, "1.0.0"); let to = getRevision(repo, "HEAD"); `
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`issues(repo, from, to);`, "ABC-3 jira [branch], ABC-2 jira [message]"},
+		{`issues(repo, from, to, {"trackers": "github"});`,
+			"#56 github [message], #12 github [message], org/repo#34 github [message]"},
+		{`issues(repo, from, to, {"trackers": ["jira", "gitlab", "azure"]});`,
+			"ABC-3 jira [branch], !45 gitlab [message], AB#678 azure [message], ABC-2 jira [message]"},
+	}
+
+	for _, tt := range tests {
+		evaluated := sr.eval(script + tt.input)
+
+		result, ok := evaluated.(*object.Array)
+		if !ok {
+			t.Errorf("%s: object is not Array. got=%T(%+v)", tt.input, evaluated, evaluated)
+			continue
+		}
+
+		got := make([]string, 0, len(result.Elements))
+		for _, element := range result.Elements {
+			pairs := element.(*object.Hash).Pairs
+			field := func(key string) string {
+				return pairs[(&object.String{Value: key}).HashKey()].Value.Inspect()
+			}
+
+			got = append(got, field("key")+" "+field("tracker")+" "+field("sources"))
+		}
+
+		if strings.Join(got, ", ") != tt.expected {
+			t.Errorf("%s: wrong issues. got=%q, want=%q", tt.input, strings.Join(got, ", "), tt.expected)
+		}
+	}
+
+	evaluated := sr.eval(script + `issues(repo, from, to, {"trackers": ["jira", "trello"]});`)
+	errObj, ok := evaluated.(*object.Error)
+	if !ok {
+		t.Fatalf("no error object returned. got=%T(%+v)", evaluated, evaluated)
+	}
+
+	expected := "unknown issue tracker 'trello' (expected one of azure, github, gitlab, jira) while executing 'issues'"
+	if errObj.Message != expected {
+		t.Errorf("wrong error message. got=%q, want=%q", errObj.Message, expected)
+	}
+}
+
+func TestFalsePositiveOptions(t *testing.T) {
+	sr := newScriptRepo(t)
+	defer sr.Cleanup()
+
+	sr.AnnotatedTag("1.0.0", sr.Commit("ABC-1 first"))
+	sr.Commit("ABC-2 store the SHA-256 of the UTF-8 files")
+	sr.Commit("XYZ-3 preABC-4post TMP-5")
+
+	path := filepath.Join(sr.Dir, "projects.txt")
+	if err := ioutil.WriteFile(path, []byte("# Known projects\nABC\nXYZ\n"), 0644); err != nil {
+		t.Fatalf("unable to write %s: %v", path, err)
+	}
+
+	script := fmt.Sprintf(`set tickets "*"; let from = getTag(repo, "1.0.0"); let to = getRevision(repo, "HEAD");
+let projects = readList(%q); `, path)
+
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{`ticketSources(repo, from, to)["TMP-5"];`, []string{"message"}},
+		{`ticketSources(repo, from, to, {"ignore": "tmp"})["TMP-5"];`, nil},
+		{`ticketSources(repo, from, to, {"ignore": ["XYZ-3"]})["XYZ-3"];`, nil},
+		{`ticketSources(repo, from, to, {"allow": projects})["TMP-5"];`, nil},
+		{`ticketSources(repo, from, to, {"allow": projects})["XYZ-3"];`, []string{"message"}},
+		{`ticketSources(repo, from, to)["SHA-256"];`, nil},
+		{`ticketSources(repo, from, to)["ABC-4"];`, nil},
+		{`projects;`, []string{"ABC", "XYZ"}},
+	}
+
+	for _, tt := range tests {
+		evaluated := sr.eval(script + tt.input)
+		if tt.expected == nil {
+			testNullObject(t, evaluated)
+			continue
+		}
+
+		result, ok := evaluated.(*object.Array)
+		if !ok {
+			t.Errorf("%s: object is not Array. got=%T(%+v)", tt.input, evaluated, evaluated)
+			continue
+		}
+
+		if len(result.Elements) != len(tt.expected) {
+			t.Errorf("%s: wrong number of elements. got=%d, want=%d", tt.input, len(result.Elements), len(tt.expected))
+			continue
+		}
+
+		for i, expected := range tt.expected {
+			testStringObject(t, result.Elements[i], expected)
+		}
+	}
+
+	evaluated := sr.eval(`readList("missing.txt");`)
+	errObj, ok := evaluated.(*object.Error)
+	if !ok {
+		t.Fatalf("no error object returned. got=%T(%+v)", evaluated, evaluated)
+	}
+
+	expected := "open missing.txt: no such file or directory while executing 'readList'"
+	if errObj.Message != expected {
+		t.Errorf("wrong error message. got=%q, want=%q", errObj.Message, expected)
+	}
+}
+
+func TestNormalizationOptions(t *testing.T) {
+	sr := newScriptRepo(t)
+	defer sr.Cleanup()
+
+	sr.AnnotatedTag("1.0.0", sr.Commit("ABC-1 first"))
+	sr.Commit("abc-12 second")
+	sr.Commit("ABC-012 third")
+	sr.Commit("OLDKEY-5 fourth (ABC-12)")
+
+	path := filepath.Join(sr.Dir, "aliases.txt")
+	if err := ioutil.WriteFile(path, []byte("OLDKEY=NEWKEY\n"), 0644); err != nil {
+		t.Fatalf("unable to write %s: %v", path, err)
+	}
+
+	script := fmt.Sprintf(`set tickets "*"; let from = getTag(repo, "1.0.0"); let to = getRevision(repo, "HEAD");
+let aliases = readAliases(%q); `, path)
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`issues(repo, from, to);`, "OLDKEY-5, ABC-12, ABC-012"},
+		{`issues(repo, from, to, {"uppercase": false});`, "OLDKEY-5, ABC-12, ABC-012, abc-12"},
+		{`issues(repo, from, to, {"stripZeros": true});`, "OLDKEY-5, ABC-12"},
+		{`issues(repo, from, to, {"aliases": aliases, "stripZeros": true});`, "NEWKEY-5, ABC-12"},
+		{`issues(repo, from, to, {"aliases": {"oldkey": "ABC"}, "ignore": "ABC-5"});`, "ABC-12, ABC-012"},
+		{`set tickets "ABC"; issues(repo, from, to);`, "ABC-12, ABC-012"},
+		{`set tickets "ABC"; issues(repo, from, to, {"uppercase": false});`, "ABC-12, ABC-012"},
+	}
+
+	for _, tt := range tests {
+		evaluated := sr.eval(script + tt.input)
+
+		result, ok := evaluated.(*object.Array)
+		if !ok {
+			t.Errorf("%s: object is not Array. got=%T(%+v)", tt.input, evaluated, evaluated)
+			continue
+		}
+
+		got := make([]string, 0, len(result.Elements))
+		for _, element := range result.Elements {
+			key := element.(*object.Hash).Pairs[(&object.String{Value: "key"}).HashKey()].Value
+			got = append(got, key.Inspect())
+		}
+
+		if strings.Join(got, ", ") != tt.expected {
+			t.Errorf("%s: wrong issues. got=%q, want=%q", tt.input, strings.Join(got, ", "), tt.expected)
+		}
+	}
+
+	errorTests := []struct {
+		input    string
+		expected string
+	}{
+		{`issues(repo, from, to, {"aliases": "OLDKEY=NEWKEY"});`,
+			"option 'aliases' must be HASH, got STRING while executing 'issues'"},
+		{`issues(repo, from, to, {"aliases": {"OLDKEY": 1}});`,
+			"option 'aliases' must only contain STRING values, got INTEGER while executing 'issues'"},
+		{`readAliases("missing.txt");`, "open missing.txt: no such file or directory while executing 'readAliases'"},
+	}
+
+	for _, tt := range errorTests {
+		evaluated := sr.eval(script + tt.input)
+
+		errObj, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("%s: no error object returned. got=%T(%+v)", tt.input, evaluated, evaluated)
+			continue
+		}
+
+		if errObj.Message != tt.expected {
+			t.Errorf("%s: wrong error message. got=%q, want=%q", tt.input, errObj.Message, tt.expected)
+		}
+	}
+}
+
+func TestInvalidPatterns(t *testing.T) {
+	sr := newScriptRepo(t)
+	defer sr.Cleanup()
+
+	sr.AnnotatedTag("1.0.0", sr.Commit("ABC-1 first"))
+	sr.Commit("ABC-2 second")
+
+	script := `let from = getTag(repo, "1.0.0"); let to = getRevision(repo, "HEAD"); `
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`extractTags(repo, "v($.$");`,
+			"invalid tag pattern 'v(([0-9]+)\\.([0-9]+)$': error parsing regexp: missing closing ): `v(([0-9]+)\\.([0-9]+)$` while executing 'extractTags'"},
+		{`getTag(repo, "1.0.0[");`,
+			"invalid tag pattern '1.0.0[': error parsing regexp: missing closing ]: `[.*` while executing 'getTag'"},
+		{`set tickets "ABC,AB[C"; diff(repo, from, to);`,
+			"invalid ticket pattern 'AB[C' in 'ABC,AB[C': error parsing regexp: missing closing ]: `[C` while executing 'diff'"},
+		{`set tickets "ABC,AB[C"; commits(repo, from, to); issues(repo, from, to);`,
+			"invalid ticket pattern 'AB[C' in 'ABC,AB[C': error parsing regexp: missing closing ]: `[C` while executing 'issues'"},
+	}
+
+	for _, tt := range tests {
+		evaluated := sr.eval(script + tt.input)
+
+		errObj, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("%s: no error object returned. got=%T(%+v)", tt.input, evaluated, evaluated)
+			continue
+		}
+
+		if errObj.Message != tt.expected {
+			t.Errorf("%s: wrong error message. got=%q, want=%q", tt.input, errObj.Message, tt.expected)
+		}
+	}
+
+	evaluated := sr.eval(script + `set tickets "A{1,3}BC,X"; ticketSources(repo, from, to)["ABC-2"];`)
+	if result, ok := evaluated.(*object.Array); !ok || len(result.Elements) != 1 {
+		t.Errorf("ABC-2 not found with a comma in a repetition. got=%T(%+v)", evaluated, evaluated)
+	}
+}
+
+func TestCompareBuiltin(t *testing.T) {
+	sr := newScriptRepo(t)
+	defer sr.Cleanup()
+
+	base := sr.Commit("ABC-1 base")
+	sr.Checkout("release", base)
+	sr.Commit("ABC-5 fix on the release branch only", "fix.txt")
+	sr.Commit("ABC-6 fix on both branches", "both.txt")
+	sr.AnnotatedTag("1.0.1", sr.Commit("XYZ-9 release notes", "notes.txt"))
+	sr.Checkout("next", base)
+	sr.Commit("ABC-7 new feature", "feature.txt")
+	sr.Commit("ABC-6 fix on both branches", "both.txt")
+
+	script := `set tickets "*"; let from = getTag(repo, "1.0.1"); let to = getRevision(repo, "HEAD"); `
+
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{`compare(repo, from, to)["added"];`, []string{"ABC-7"}},
+		{`compare(repo, from, to)["removed"];`, []string{"XYZ-9", "ABC-5"}},
+		{`compare(repo, to, from)["added"];`, []string{"XYZ-9", "ABC-5"}},
+		{`compare(repo, from, to, {"include": "fix.txt"})["removed"];`, []string{"ABC-5"}},
+		{`compare(repo, from, to, {"include": "fix.txt"})["added"];`, []string{}},
+		{`compare(repo, getLatestTag(repo, 0), to)["removed"];`, []string{}},
+		{`compare(repo, from, to, {"sides": "added"})["added"];`, []string{"ABC-7"}},
+		{`diff(repo, from, to, {"sides": "both"})["removed"];`, []string{"XYZ-9", "ABC-5"}},
+	}
+
+	for _, tt := range tests {
+		evaluated := sr.eval(script + tt.input)
+
+		result, ok := evaluated.(*object.Array)
+		if !ok {
+			t.Errorf("%s: object is not Array. got=%T(%+v)", tt.input, evaluated, evaluated)
+			continue
+		}
+
+		if len(result.Elements) != len(tt.expected) {
+			t.Errorf("%s: wrong number of elements. got=%d, want=%d", tt.input, len(result.Elements), len(tt.expected))
+			continue
+		}
+
+		for i, expected := range tt.expected {
+			testStringObject(t, result.Elements[i], expected)
+		}
+	}
+
+	evaluated := sr.eval(script + `diff(repo, from, to, {"sides": "all"});`)
+	errObj, ok := evaluated.(*object.Error)
+	if !ok {
+		t.Fatalf("no error object returned. got=%T(%+v)", evaluated, evaluated)
+	}
+
+	expected := "unknown sides 'all' (expected 'added', 'removed' or 'both') while executing 'diff'"
+	if errObj.Message != expected {
+		t.Errorf("wrong error message. got=%q, want=%q", errObj.Message, expected)
+	}
+}
+
+func TestDiffBuiltin(t *testing.T) {
+	sr := newScriptRepo(t)
+	defer sr.Cleanup()
+
+	base := sr.Commit("ABC-1 base")
+	sr.AnnotatedTag("1.0.0", base)
+	sr.Commit("ABC-2 second")
+	sr.Commit("XYZ-3 third (ABC-2)")
+
+	script := `set tickets "*"; let from = getTag(repo, "1.0.0"); let to = getRevision(repo, "HEAD"); `
+
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{`diff(repo, from, to);`, []string{"XYZ-3", "ABC-2"}},
+		{`let t = diff(repo, from, to); len(t);`, nil},
+		{`diff(repo, getLatestTag(repo, 0), to);`, []string{"XYZ-3", "ABC-2", "ABC-1"}},
+		{`set tickets "XYZ"; diff(repo, from, to);`, []string{"XYZ-3"}},
+		{`diff(repo, from, to, {"sides": "both"})["added"];`, []string{"XYZ-3", "ABC-2"}},
+		{`diff(repo, from, to, {"sides": "both"})["removed"];`, []string{}},
+		{`diff(repo, from, to, {"details": true})["tickets"];`, []string{"XYZ-3", "ABC-2"}},
+		{`diff(repo, from, to, {"details": true, "sides": "added"})["tickets"];`, []string{"XYZ-3", "ABC-2"}},
+		{`let c = diff(repo, from, to, {"commits": true})["commits"]["ABC-2"]; [c[0]["subject"], c[1]["subject"]];`,
+			[]string{"XYZ-3 third (ABC-2)", "ABC-2 second"}},
+	}
+
+	for _, tt := range tests {
+		evaluated := sr.eval(script + tt.input)
+		if tt.expected == nil {
+			testIntegerObject(t, evaluated, 2)
+			continue
+		}
+
+		result, ok := evaluated.(*object.Array)
+		if !ok {
+			t.Errorf("%s: object is not Array. got=%T(%+v)", tt.input, evaluated, evaluated)
+			continue
+		}
+
+		if len(result.Elements) != len(tt.expected) {
+			t.Errorf("%s: wrong number of elements. got=%d, want=%d", tt.input, len(result.Elements), len(tt.expected))
+			continue
+		}
+
+		for i, expected := range tt.expected {
+			testStringObject(t, result.Elements[i], expected)
+		}
+	}
+
+	testNullObject(t, sr.eval(script+`diff(repo, from, to, {"sides": "added"})["removed"];`))
+	testNullObject(t, sr.eval(script+`diff(repo, from, to, {"sides": "removed"})["added"];`))
+	testNullObject(t, sr.eval(script+`diff(repo, from, to, {"details": true})["commits"];`))
+
+	evaluated := sr.eval(script + `diff(repo, from, to, {"sides": "removed"})["to"];`)
+	if evaluated.Inspect() != "HEAD" {
+		t.Errorf("wrong 'to' of the sides. got=%s", evaluated.Inspect())
+	}
+
+	evaluated = sr.eval(script + `diff(repo, from, to, {"details": true})["repo"];`)
+	if _, ok := evaluated.(*object.Repo); !ok {
+		t.Errorf("wrong 'repo' of the details. got=%T(%+v)", evaluated, evaluated)
+	}
+}
+
+func TestRenderBuiltin(t *testing.T) {
+	sr := newScriptRepo(t)
+	defer sr.Cleanup()
+
+	base := sr.Commit("ABC-1 base")
+	sr.AnnotatedTag("1.0.0", base)
+	second := sr.Commit("ABC-2 second").String()[:7]
+	third := sr.Commit("XYZ-3 third (ABC-2)").String()[:7]
+
+	script := `set tickets "*"; let from = getTag(repo, "1.0.0"); let to = getRevision(repo, "HEAD"); `
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`render("{{range .Projects}}{{.Key}}:{{range .Tickets}} {{.Key}}{{end}};{{end}}", diff(repo, from, to));`,
+			"ABC: ABC-2;XYZ: XYZ-3;"},
+		{`let d = diff(repo, from, to, {"commits": true}); render("{{.From.Name}}..{{.To.Name}}{{range .Projects}}{{range .Tickets}} {{len .Commits}}{{end}}{{end}}", d);`,
+			"1.0.0..HEAD 2 1"},
+		{`render(diff(repo, from, to, {"commits": true}));`,
+			"# Release notes HEAD\n\nChanges since 1.0.0 (2020-01-01).\n\n## ABC\n\n- ABC-2\n" +
+				"  - XYZ-3 third (ABC-2) (" + third + ")\n  - ABC-2 second (" + second + ")\n\n" +
+				"## XYZ\n\n- XYZ-3\n  - XYZ-3 third (ABC-2) (" + third + ")\n"},
+	}
+
+	for _, tt := range tests {
+		testStringObject(t, sr.eval(script+tt.input), tt.expected)
+	}
+
+	errorTests := []struct {
+		input    string
+		expected string
+	}{
+		{`render("{{.Version}}", diff(repo, from, to));`, "unable to render the release notes: template: " +
+			"release-notes:1:2: executing \"release-notes\" at <.Version>: can't evaluate field Version in type " +
+			"*output.ReleaseNotes while executing 'render'"},
+		{`render(3);`, "unsupported result of the script (expected the value returned by 'diff', got INTEGER) " +
+			"while executing 'render'"},
+		{`render(1, []);`, "Unable to convert args[0] to *object.String while executing 'render'"},
+	}
+
+	for _, tt := range errorTests {
+		errObj, ok := sr.eval(script + tt.input).(*object.Error)
+		if !ok {
+			t.Errorf("%s: no error object returned", tt.input)
+			continue
+		}
+
+		if errObj.Message != tt.expected {
+			t.Errorf("%s: wrong error message. got=%q, want=%q", tt.input, errObj.Message, tt.expected)
+		}
+	}
+}
+
+func TestUpdateChangelogBuiltin(t *testing.T) {
+	sr := newScriptRepo(t)
+	defer sr.Cleanup()
+
+	sr.AnnotatedTag("1.0.0", sr.Commit("ABC-1 base"))
+	sr.Commit("feat: ABC-2 add the exporter")
+	sr.AnnotatedTag("v1.1.0", sr.Commit("fix(parser): ABC-3 handle empty input"))
+
+	path := filepath.Join(sr.Dir, "CHANGELOG.md")
+	if err := ioutil.WriteFile(path, []byte("# Changelog\n\n## [Unreleased]\n"), 0644); err != nil {
+		t.Fatalf("unable to write %s: %v", path, err)
+	}
+
+	script := fmt.Sprintf(`set tickets "*"; let path = %q; let from = getTag(repo, "1.0.0"); `, path)
+	update := `updateChangelog(path, diff(repo, from, getTag(repo, "v1.1.0"), {"commits": true}));`
+
+	testBooleanObject(t, sr.eval(script+update), true)
+	testBooleanObject(t, sr.eval(script+update), false)
+
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("unable to read %s: %v", path, err)
+	}
+
+	expected := "# Changelog\n\n## [Unreleased]\n\n## [1.1.0] - 2020-01-01\n### Added\n- ABC-2 add the exporter\n" +
+		"### Fixed\n- ABC-3 handle empty input\n"
+	if string(content) != expected {
+		t.Errorf("wrong changelog.\ngot=%q\nwant=%q", string(content), expected)
+	}
+
+	head := `diff(repo, from, getRevision(repo, "HEAD"), {"details": true})`
+	testBooleanObject(t, sr.eval(script+`updateChangelog(path, `+head+`, "1.2.0");`), true)
+
+	errorTests := []struct {
+		input    string
+		expected string
+	}{
+		{`updateChangelog(path, diff(repo, from, getRevision(repo, "HEAD"), {"details": true}));`,
+			"unable to name the changelog section: 'HEAD' isn't a tag, the version must be specified while executing 'updateChangelog'"},
+		{`updateChangelog(path, diff(repo, from, getRevision(repo, "HEAD")), "1.3.0");`,
+			"unable to date the changelog section: the range is unknown (see the 'details' option of 'diff') while executing 'updateChangelog'"},
+		{`updateChangelog(1, []);`, "Unable to convert args[0] to *object.String while executing 'updateChangelog'"},
+		{`updateChangelog(path, [], 1);`, "Unable to convert args[2] to *object.String while executing 'updateChangelog'"},
+	}
+
+	for _, tt := range errorTests {
+		errObj, ok := sr.eval(script + tt.input).(*object.Error)
+		if !ok {
+			t.Errorf("%s: no error object returned", tt.input)
+			continue
+		}
+
+		if errObj.Message != tt.expected {
+			t.Errorf("%s: wrong error message. got=%q, want=%q", tt.input, errObj.Message, tt.expected)
+		}
+	}
+}
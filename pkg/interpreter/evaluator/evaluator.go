@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"github.com/TurnsCoffeeIntoScripts/git-log-issue-finder/pkg/interpreter/ast"
 	"github.com/TurnsCoffeeIntoScripts/git-log-issue-finder/pkg/interpreter/object"
+	"io"
+	"os"
 )
 
 // Constant defining object that are frequently used. This allows the interpreter to reuse these object instead
@@ -19,6 +21,10 @@ var (
 	FALSE = &object.Boolean{Value: false}
 )
 
+// Output is the writer of the 'print' builtin. The command line redirects it to the standard error when the result
+// of the script is printed as JSON, so that the messages of the script don't corrupt the document.
+var Output io.Writer = os.Stdout
+
 // Eval is the main function of the evaluator. It determines which function to call based on the type of node received
 func Eval(node ast.Node, env *object.Environment) object.Object {
 	switch node := node.(type) {
@@ -312,6 +318,8 @@ func evalIndexExpression(left object.Object, index object.Object) object.Object
 		return evalArrayIndexExpression(left, index)
 	case left.Type() == object.HashObj:
 		return evalHashIndexExpression(left, index)
+	case left.Type() == object.CommitObj && index.Type() == object.StringObj:
+		return evalCommitIndexExpression(left, index)
 	default:
 		return newError("index operator not supported: %s", left.Type())
 	}
@@ -345,6 +353,17 @@ func evalHashIndexExpression(hash, index object.Object) object.Object {
 	return pair.Value
 }
 
+func evalCommitIndexExpression(commit, index object.Object) object.Object {
+	field := index.(*object.String).Value
+
+	value, ok := commit.(*object.Commit).Field(field)
+	if !ok {
+		return newError("unknown field of %s: %s", commit.Type(), field)
+	}
+
+	return value
+}
+
 func evalExpressions(exps []ast.Expression, env *object.Environment) []object.Object {
 	var result []object.Object
 
@@ -4,6 +4,10 @@ import (
 	"github.com/TurnsCoffeeIntoScripts/git-log-issue-finder/pkg/interpreter/lexer"
 	"github.com/TurnsCoffeeIntoScripts/git-log-issue-finder/pkg/interpreter/object"
 	"github.com/TurnsCoffeeIntoScripts/git-log-issue-finder/pkg/interpreter/parser"
+	"github.com/TurnsCoffeeIntoScripts/git-log-issue-finder/pkg/script"
+	"io/ioutil"
+	"os"
+	"strings"
 	"testing"
 )
 
@@ -558,6 +562,181 @@ func TestRepoExpression(t *testing.T) {
 	}
 }
 
+func TestInitRepoErrors(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedMessage string
+	}{
+		{
+			`set repopath "/this/path/does/not/exist"; initRepo();`,
+			"unable to open repository at '/this/path/does/not/exist': repository does not exist while executing 'initRepo'",
+		},
+		{
+			`set repopath "/this/path/does/not/exist"; let repo = initRepo(); whichRepo();`,
+			"unable to open repository at '/this/path/does/not/exist': repository does not exist while executing 'initRepo'",
+		},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		errObj, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("no error object returned. got=%T(%+v)", evaluated, evaluated)
+			continue
+		}
+
+		if errObj.Message != tt.expectedMessage {
+			t.Errorf("wrong error message. expected=%q, got=%q", tt.expectedMessage, errObj.Message)
+		}
+	}
+}
+
+// evalPredefinedScript evaluates a predefined script in the directory of the repository (the scripts open the
+// repository at '.') with the variables that the command line sets from the input flags
+func evalPredefinedScript(t *testing.T, dir string, input string, vars map[string]object.Object) object.Object {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("unable to get the working directory: %v", err)
+	}
+
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("unable to change the working directory: %v", err)
+	}
+	defer func() { _ = os.Chdir(wd) }()
+
+	output := Output
+	Output = ioutil.Discard
+	defer func() { Output = output }()
+
+	env := object.NewEnvironmentWithParams("*")
+	env.Set("forcefetch", FALSE)
+	env.Set("fetchopts", object.NewStringHash(map[string]object.Object{}))
+	env.Set("diffopts", object.NewStringHash(map[string]object.Object{}))
+	for name, value := range vars {
+		env.Set(name, value)
+	}
+
+	p := parser.NewWithOptions(lexer.New(input), false)
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		t.Fatalf("unable to parse the script: %v", p.Errors())
+	}
+
+	return Eval(program, env)
+}
+
+// testTickets checks that the result is the array of the expected tickets
+func testTickets(t *testing.T, name string, obj object.Object, expected []string) {
+	result, ok := obj.(*object.Array)
+	if !ok {
+		t.Errorf("%s: object is not Array. got=%T (%+v)", name, obj, obj)
+		return
+	}
+
+	tickets := make([]string, 0, len(result.Elements))
+	for _, element := range result.Elements {
+		tickets = append(tickets, element.Inspect())
+	}
+
+	if strings.Join(tickets, ",") != strings.Join(expected, ",") {
+		t.Errorf("%s: wrong tickets. got=%v, want=%v", name, tickets, expected)
+	}
+}
+
+func TestPredefinedScripts(t *testing.T) {
+	sr := newScriptRepo(t)
+	defer sr.Cleanup()
+
+	sr.AnnotatedTag("1.0.0", sr.Commit("ABC-1 first"))
+	second := sr.Commit("ABC-2 second")
+	sr.AnnotatedTag("1.1.0-rc.1", second)
+	sr.AnnotatedTag("1.1.0-build.1", second)
+	third := sr.Commit("ABC-3 third")
+	sr.AnnotatedTag("1.1.0-rc.2", third)
+	sr.AnnotatedTag("1.1.0-build.2", third)
+	sr.AnnotatedTag("1.1.0", third)
+	sr.Commit("ABC-4 fourth")
+	sr.Commit("ABC-5 documentation", "docs/README.md")
+
+	tests := []struct {
+		name     string
+		input    string
+		vars     map[string]object.Object
+		expected []string
+	}{
+		{"DiffLatestSemverWithLatestBuilds", script.DiffLatestSemverWithLatestBuilds, nil, []string{"ABC-3"}},
+		{"DiffLatestSemverWithLatestRCs", script.DiffLatestSemverWithLatestRCs, nil, []string{"ABC-3"}},
+		{"DiffLatestSemver", script.DiffLatestSemver, nil, []string{"ABC-3", "ABC-2"}},
+		{"DiffLatestSemverToHead", script.DiffLatestSemverToHead, nil, []string{"ABC-5", "ABC-4"}},
+		{"DiffLatestSemverToHead with diffopts", script.DiffLatestSemverToHead,
+			map[string]object.Object{"diffopts": object.NewStringHash(map[string]object.Object{
+				"exclude": &object.Array{Elements: []object.Object{&object.String{Value: "docs/**"}}},
+			})},
+			[]string{"ABC-4"}},
+	}
+
+	for _, tt := range tests {
+		testTickets(t, tt.name, evalPredefinedScript(t, sr.Dir, tt.input, tt.vars), tt.expected)
+	}
+
+	// A hotfix of the previous version tagged after 1.1.0 isn't the latest version
+	sr.AnnotatedTag("1.0.1", second)
+	evaluated := evalPredefinedScript(t, sr.Dir, script.DiffLatestSemverToHead, nil)
+	testTickets(t, "DiffLatestSemverToHead after a hotfix", evaluated, []string{"ABC-5", "ABC-4"})
+}
+
+func TestPredefinedScriptsWithoutTags(t *testing.T) {
+	sr := newScriptRepo(t)
+	defer sr.Cleanup()
+
+	sr.Commit("ABC-1 first")
+	sr.Commit("ABC-2 second")
+
+	// Without any released version, every commit reachable from HEAD is part of the diff
+	evaluated := evalPredefinedScript(t, sr.Dir, script.DiffLatestSemverToHead, nil)
+	testTickets(t, "DiffLatestSemverToHead", evaluated, []string{"ABC-2", "ABC-1"})
+}
+
+func TestPredefinedScriptsForceFetch(t *testing.T) {
+	upstream := newScriptRepo(t)
+	defer upstream.Cleanup()
+
+	upstream.AnnotatedTag("1.0.0", upstream.Commit("ABC-1 first"))
+
+	local := newScriptRepo(t)
+	defer local.Cleanup()
+
+	local.Git("remote", "add", "upstream", upstream.Dir)
+	local.Git("fetch", "-q", "--tags", "upstream")
+	local.Git("checkout", "-q", "-b", "main", "upstream/master")
+
+	// The new version is only known after the fetch
+	upstream.AnnotatedTag("1.1.0", upstream.Commit("ABC-2 second"))
+
+	fetchopts := object.NewStringHash(map[string]object.Object{
+		"remote": &object.String{Value: "upstream"},
+		"tags":   TRUE,
+	})
+
+	evaluated := evalPredefinedScript(t, local.Dir, script.DiffLatestSemver,
+		map[string]object.Object{"fetchopts": fetchopts})
+	testTickets(t, "without forcefetch", evaluated, []string{"ABC-1"})
+
+	evaluated = evalPredefinedScript(t, local.Dir, script.DiffLatestSemver, map[string]object.Object{
+		"forcefetch": TRUE,
+		"fetchopts":  fetchopts,
+	})
+	testTickets(t, "with forcefetch", evaluated, []string{"ABC-2"})
+
+	// The options of the fetch are those of the fetchopts variable
+	evaluated = evalPredefinedScript(t, local.Dir, script.DiffLatestSemver,
+		map[string]object.Object{"forcefetch": TRUE})
+	if errObj, ok := evaluated.(*object.Error); !ok || !strings.Contains(errObj.Message, "unable to find remote 'origin'") {
+		t.Errorf("the default remote should be fetched without fetchopts. got=%T(%+v)", evaluated, evaluated)
+	}
+}
+
 func testEval(input string) object.Object {
 	l := lexer.New(input)
 	p := parser.New(l)
@@ -0,0 +1,150 @@
+package evaluator
+
+import (
+	"fmt"
+	"github.com/TurnsCoffeeIntoScripts/git-log-issue-finder/pkg/helpers"
+	"github.com/TurnsCoffeeIntoScripts/git-log-issue-finder/pkg/interpreter/object"
+	gitobject "github.com/go-git/go-git/v5/plumbing/object"
+	"time"
+)
+
+// Some builtins accept an optional hash as their last argument to specify options, for example:
+//	diff(repo, from, to, {"mode": "symmetric"});
+// The following functions read the values of such a hash. A nil hash (no options given) always yields the default.
+
+// optionsArg converts the optional argument at the specified index to an options hash.
+// It returns a nil hash (and no error) when the argument is absent or NULL.
+func optionsArg(builtin string, args []object.Object, index int) (*object.Hash, *object.Error) {
+	if index >= len(args) || args[index] == NULL {
+		return nil, nil
+	}
+
+	opts, ok := args[index].(*object.Hash)
+	if !ok {
+		return nil, newError("Unable to convert args[%d] to *object.Hash while executing '%s'", index, builtin)
+	}
+
+	return opts, nil
+}
+
+func optionValue(opts *object.Hash, key string) (object.Object, bool) {
+	if opts == nil {
+		return nil, false
+	}
+
+	pair, ok := opts.Pairs[(&object.String{Value: key}).HashKey()]
+	if !ok || pair.Value == NULL {
+		return nil, false
+	}
+
+	return pair.Value, true
+}
+
+func optionString(opts *object.Hash, key string, fallback string) (string, error) {
+	value, ok := optionValue(opts, key)
+	if !ok {
+		return fallback, nil
+	}
+
+	str, ok := value.(*object.String)
+	if !ok {
+		return fallback, fmt.Errorf("option '%s' must be STRING, got %s", key, value.Type())
+	}
+
+	return str.Value, nil
+}
+
+func optionBool(opts *object.Hash, key string, fallback bool) (bool, error) {
+	value, ok := optionValue(opts, key)
+	if !ok {
+		return fallback, nil
+	}
+
+	boolean, ok := value.(*object.Boolean)
+	if !ok {
+		return fallback, fmt.Errorf("option '%s' must be BOOLEAN, got %s", key, value.Type())
+	}
+
+	return boolean.Value, nil
+}
+
+// optionStrings accepts either an array of strings or a single string of comma separated values
+func optionStrings(opts *object.Hash, key string) ([]string, error) {
+	value, ok := optionValue(opts, key)
+	if !ok {
+		return nil, nil
+	}
+
+	switch value := value.(type) {
+	case *object.String:
+		return helpers.SplitList(value.Value), nil
+	case *object.Array:
+		values := make([]string, 0, len(value.Elements))
+		for _, element := range value.Elements {
+			str, ok := element.(*object.String)
+			if !ok {
+				return nil, fmt.Errorf("option '%s' must only contain STRING, got %s", key, element.Type())
+			}
+			values = append(values, str.Value)
+		}
+		return values, nil
+	default:
+		return nil, fmt.Errorf("option '%s' must be STRING or ARRAY, got %s", key, value.Type())
+	}
+}
+
+// optionStringMap accepts a hash of strings indexed by strings
+func optionStringMap(opts *object.Hash, key string) (map[string]string, error) {
+	value, ok := optionValue(opts, key)
+	if !ok {
+		return nil, nil
+	}
+
+	hash, ok := value.(*object.Hash)
+	if !ok {
+		return nil, fmt.Errorf("option '%s' must be HASH, got %s", key, value.Type())
+	}
+
+	values := make(map[string]string, len(hash.Pairs))
+	for _, pair := range hash.Pairs {
+		k, ok := pair.Key.(*object.String)
+		if !ok {
+			return nil, fmt.Errorf("option '%s' must only contain STRING keys, got %s", key, pair.Key.Type())
+		}
+
+		v, ok := pair.Value.(*object.String)
+		if !ok {
+			return nil, fmt.Errorf("option '%s' must only contain STRING values, got %s", key, pair.Value.Type())
+		}
+
+		values[k.Value] = v.Value
+	}
+
+	return values, nil
+}
+
+// optionSignature reads an identity given as a hash: {"name": "...", "email": "..."}. The date is the current time.
+func optionSignature(opts *object.Hash, key string) (gitobject.Signature, error) {
+	signature := gitobject.Signature{When: time.Now()}
+
+	value, ok := optionValue(opts, key)
+	if !ok {
+		return signature, nil
+	}
+
+	identity, ok := value.(*object.Hash)
+	if !ok {
+		return signature, fmt.Errorf("option '%s' must be HASH, got %s", key, value.Type())
+	}
+
+	var err error
+	if signature.Name, err = optionString(identity, "name", ""); err != nil {
+		return signature, fmt.Errorf("%v in option '%s'", err, key)
+	}
+
+	if signature.Email, err = optionString(identity, "email", ""); err != nil {
+		return signature, fmt.Errorf("%v in option '%s'", err, key)
+	}
+
+	return signature, nil
+}
@@ -0,0 +1,248 @@
+package evaluator
+
+import (
+	"github.com/TurnsCoffeeIntoScripts/git-log-issue-finder/pkg/interpreter/object"
+	"github.com/TurnsCoffeeIntoScripts/git-log-issue-finder/pkg/scl"
+	"github.com/go-git/go-git/v5/plumbing"
+	gitobject "github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// rangeArgs holds the arguments shared by the builtins working on a range of commits: (repo, from, to[, opts])
+//	- 'from' and 'to' can be any object.Committish (tags, revisions, commits)
+//	- 'from' can also be NULL (ex: no tag matched yet), meaning that every commit reachable from 'to' is in the range
+//	- 'opts' is an optional hash of options
+type rangeArgs struct {
+	builtin string
+	repo    *object.Repo
+	from    object.Object
+	to      object.Committish
+	opts    *object.Hash
+}
+
+// parseTicketRangeArgs converts the arguments of the builtins that require the 'tickets' regex from the environment:
+// (tickets, repo, from, to[, opts])
+func parseTicketRangeArgs(builtin string, args []object.Object) (string, *rangeArgs, *object.Error) {
+	if len(args) == 0 {
+		return "", nil, newError("wrong number of arguments. got=%d, want=3 or 4", len(args))
+	}
+
+	ticketRegex, ok := args[0].(*object.String)
+	if !ok {
+		return "", nil, newError("Unable to convert args[0] to *object.String while executing '%s'", builtin)
+	}
+
+	ra, err := parseRangeArgs(builtin, args, 1)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return ticketRegex.Value, ra, nil
+}
+
+// parseRangeArgs converts the arguments starting at the index 'first' (the repo). Any argument before this index is
+// left to the builtin (ex: the environment value when RequireEnv is true).
+func parseRangeArgs(builtin string, args []object.Object, first int) (*rangeArgs, *object.Error) {
+	if len(args) != first+3 && len(args) != first+4 {
+		return nil, newError("wrong number of arguments. got=%d, want=3 or 4", len(args)-first)
+	}
+
+	ra := &rangeArgs{builtin: builtin, from: args[first+1]}
+
+	var ok bool
+	if ra.repo, ok = args[first].(*object.Repo); !ok {
+		return nil, newError("Unable to convert args[%d] to *object.Repo while executing '%s'", first, builtin)
+	}
+
+	if _, ok = ra.from.(object.Committish); !ok && ra.from != NULL {
+		return nil, newError("Unable to convert args[%d] ('from') to a TAG, a REVISION or a COMMIT while executing '%s'",
+			first+1, builtin)
+	}
+
+	if ra.to, ok = args[first+2].(object.Committish); !ok {
+		return nil, newError("Unable to convert args[%d] ('to') to a TAG, a REVISION or a COMMIT while executing '%s'",
+			first+2, builtin)
+	}
+
+	var err *object.Error
+	if ra.opts, err = optionsArg(builtin, args, first+3); err != nil {
+		return nil, err
+	}
+
+	return ra, nil
+}
+
+func (ra *rangeArgs) fromHash() plumbing.Hash {
+	if from, ok := ra.from.(object.Committish); ok {
+		return from.ResolvedCommit().Hash
+	}
+
+	return plumbing.ZeroHash
+}
+
+// optionError wraps an error related to one of the options of the builtin
+func (ra *rangeArgs) optionError(err error) *object.Error {
+	return newError("%s while executing '%s'", err.Error(), ra.builtin)
+}
+
+// walk walks the history from both sides of the range until the common ancestors
+func (ra *rangeArgs) walk() (*scl.CommitRange, *object.Error) {
+	commitRange, err := ra.repo.Repo.WalkRange(ra.fromHash(), ra.to.ResolvedCommit().Hash)
+	if err != nil {
+		return nil, newError("an error occured while walking the commit history between '%s' and '%s': %v",
+			ra.from.Inspect(), ra.to.Inspect(), err)
+	}
+
+	return commitRange, nil
+}
+
+// commitSelection contains the commits of a range once the options of the builtin are applied
+type commitSelection struct {
+	commits    []*gitobject.Commit // Commits to consider
+	reverted   []*gitobject.Commit // Commits reverted by another commit of the range (see scl.CancelReverts)
+	backported []*gitobject.Commit // Commits already applied on the 'from' side (see scl.RemoveCherryPicks)
+}
+
+// selectionOptions are the options of the builtin that select the commits of the range
+type selectionOptions struct {
+	mode          scl.DiffMode
+	cancelReverts bool
+	patchID       bool
+	filter        *scl.PathFilter
+}
+
+// selectionOptions reads the 'mode', 'cancelReverts', 'patchId', 'include' and 'exclude' options
+func (ra *rangeArgs) selectionOptions() (*selectionOptions, *object.Error) {
+	modeName, err := optionString(ra.opts, "mode", "range")
+	if err != nil {
+		return nil, ra.optionError(err)
+	}
+
+	opts := &selectionOptions{}
+	if opts.mode, err = scl.ParseDiffMode(modeName); err != nil {
+		return nil, ra.optionError(err)
+	}
+
+	if opts.cancelReverts, err = optionBool(ra.opts, "cancelReverts", true); err != nil {
+		return nil, ra.optionError(err)
+	}
+
+	if opts.patchID, err = optionBool(ra.opts, "patchId", false); err != nil {
+		return nil, ra.optionError(err)
+	}
+
+	if opts.filter, err = ra.pathFilter(); err != nil {
+		return nil, ra.optionError(err)
+	}
+
+	return opts, nil
+}
+
+// selection returns the commits of the range that are relevant for the 'mode' option (see scl.DiffMode) and that
+// modified the paths selected by the 'include' and 'exclude' options (see scl.PathFilter). Unless the 'cancelReverts'
+// option is false, the commits reverted within the range are set apart and the revert commits are ignored.
+// When the 'patchId' option is true (or when forced by the builtin), the commits having an equivalent commit on the
+// other side of the range are set apart as well.
+func (ra *rangeArgs) selection(forcePatchID bool) (*commitSelection, *object.Error) {
+	opts, err := ra.selectionOptions()
+	if err != nil {
+		return nil, err
+	}
+
+	commitRange, backported, err := ra.walkSelection(opts, forcePatchID)
+	if err != nil {
+		return nil, err
+	}
+
+	selection, err := ra.selectCommits(commitRange.Commits(opts.mode), opts)
+	if err != nil {
+		return nil, err
+	}
+
+	selection.backported = backported
+	return selection, nil
+}
+
+// sides returns the commits selected on each side of the range (see selection): the commits reachable only from
+// 'to' and the ones reachable only from 'from'. The 'mode' option is ignored.
+func (ra *rangeArgs) sides() (*commitSelection, *commitSelection, *object.Error) {
+	opts, err := ra.selectionOptions()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	commitRange, _, err := ra.walkSelection(opts, false)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	toSide, err := ra.selectCommits(commitRange.ToOnly, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	fromSide, err := ra.selectCommits(commitRange.FromOnly, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return toSide, fromSide, nil
+}
+
+// walkSelection walks the range and, when the 'patchId' option is true (or when forced), sets apart the commits
+// having an equivalent commit on the other side of the range. The commits set apart that modified the selected paths
+// are returned as well.
+func (ra *rangeArgs) walkSelection(opts *selectionOptions, force bool) (*scl.CommitRange, []*gitobject.Commit, *object.Error) {
+	commitRange, walkErr := ra.walk()
+	if walkErr != nil {
+		return nil, nil, walkErr
+	}
+
+	backported := make([]*gitobject.Commit, 0)
+	if !opts.patchID && !force {
+		return commitRange, backported, nil
+	}
+
+	var err error
+	if commitRange, backported, err = scl.RemoveCherryPicks(commitRange); err != nil {
+		return nil, nil, newError("%s while executing '%s'", err.Error(), ra.builtin)
+	}
+
+	if backported, err = ra.repo.Repo.FilterCommits(backported, opts.filter); err != nil {
+		return nil, nil, newError("%s while executing '%s'", err.Error(), ra.builtin)
+	}
+
+	return commitRange, backported, nil
+}
+
+// selectCommits keeps the commits that modified the selected paths and, unless the 'cancelReverts' option is false,
+// sets apart the commits reverted by another one
+func (ra *rangeArgs) selectCommits(commits []*gitobject.Commit, opts *selectionOptions) (*commitSelection, *object.Error) {
+	selection := &commitSelection{reverted: make([]*gitobject.Commit, 0), backported: make([]*gitobject.Commit, 0)}
+
+	var err error
+	if selection.commits, err = ra.repo.Repo.FilterCommits(commits, opts.filter); err != nil {
+		return nil, newError("%s while executing '%s'", err.Error(), ra.builtin)
+	}
+
+	if opts.cancelReverts {
+		result := scl.CancelReverts(selection.commits)
+		selection.commits, selection.reverted = result.Kept, result.Reverted
+	}
+
+	return selection, nil
+}
+
+// pathFilter reads the 'include' and 'exclude' options (globs of the paths that the commits must modify)
+func (ra *rangeArgs) pathFilter() (*scl.PathFilter, error) {
+	include, err := optionStrings(ra.opts, "include")
+	if err != nil {
+		return nil, err
+	}
+
+	exclude, err := optionStrings(ra.opts, "exclude")
+	if err != nil {
+		return nil, err
+	}
+
+	return scl.NewPathFilter(include, exclude)
+}
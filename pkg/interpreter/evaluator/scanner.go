@@ -0,0 +1,347 @@
+package evaluator
+
+import (
+	"github.com/TurnsCoffeeIntoScripts/git-log-issue-finder/pkg/interpreter/object"
+	"github.com/TurnsCoffeeIntoScripts/git-log-issue-finder/pkg/issue"
+	"github.com/TurnsCoffeeIntoScripts/git-log-issue-finder/pkg/scl"
+	gitobject "github.com/go-git/go-git/v5/plumbing/object"
+	"strings"
+)
+
+// ticketScanner extracts the tickets (the issues of the selected trackers, see issue.Matcher) referenced by the
+// commits of a range. The tickets are looked for in several
+// sources of each commit:
+//	- message: the commit message
+//	- trailer: the values of the named trailers, scanned instead of the message when trailer keys are specified
+//	- branch: the name of the branch merged by a merge commit (its subject is not part of the 'message' source)
+//	- note: the note attached to the commit (see 'git notes')
+type ticketScanner struct {
+	matcher     *issue.Matcher
+	notes       *scl.Notes // nil when the notes are not scanned
+	trailerKeys []string   // Only scan the values of these trailers (case insensitive) when not empty
+	branches    bool       // Scan the names of the merged branches
+}
+
+// Sources of the tickets (see ticketScanner)
+const (
+	sourceMessage = "message"
+	sourceTrailer = "trailer"
+	sourceBranch  = "branch"
+	sourceNote    = "note"
+)
+
+// sourceText is a text of a commit that is scanned for tickets
+type sourceText struct {
+	source string
+	text   string
+}
+
+// tickets returns the tickets referenced by the commits selected in the range (see rangeArgs.selection)
+func (ra *rangeArgs) tickets(ticketRegex string) ([]string, *object.Error) {
+	selection, err := ra.selection(false)
+	if err != nil {
+		return nil, err
+	}
+
+	scanner, err := ra.scanner(ticketRegex)
+	if err != nil {
+		return nil, err
+	}
+
+	ticketSlice, scanErr := scanner.scan(selection.commits)
+	if scanErr != nil {
+		return nil, newError("%s while executing '%s'", scanErr.Error(), ra.builtin)
+	}
+
+	return ticketSlice, nil
+}
+
+// compare returns the tickets that are new in 'to' (referenced by the commits reachable only from 'to' but not by
+// the ones reachable only from 'from') and the tickets that are only in 'from' (ex: a fix on a diverged release
+// branch that never reached 'to')
+func (ra *rangeArgs) compare(ticketRegex string) ([]string, []string, *object.Error) {
+	toSide, fromSide, err := ra.sides()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	scanner, err := ra.scanner(ticketRegex)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	added, scanErr := scanner.scanApart(toSide.commits, fromSide.commits)
+	if scanErr != nil {
+		return nil, nil, newError("%s while executing '%s'", scanErr.Error(), ra.builtin)
+	}
+
+	removed, scanErr := scanner.scanApart(fromSide.commits, toSide.commits)
+	if scanErr != nil {
+		return nil, nil, newError("%s while executing '%s'", scanErr.Error(), ra.builtin)
+	}
+
+	return added, removed, nil
+}
+
+// details returns the hash describing the range and its tickets: the 'repo', the 'from' and 'to' sides, the
+// 'tickets' and, when requested, the 'commits' referencing each ticket (a hash of arrays of commits indexed by key)
+func (ra *rangeArgs) details(ticketRegex string, withCommits bool) (*object.Hash, *object.Error) {
+	selection, err := ra.selection(false)
+	if err != nil {
+		return nil, err
+	}
+
+	scanner, err := ra.scanner(ticketRegex)
+	if err != nil {
+		return nil, err
+	}
+
+	result, scanErr := scanner.scanIssues(selection.commits)
+	if scanErr != nil {
+		return nil, newError("%s while executing '%s'", scanErr.Error(), ra.builtin)
+	}
+
+	pairs := map[string]object.Object{
+		"repo":    ra.repo,
+		"from":    ra.from,
+		"to":      ra.to,
+		"tickets": stringArray(result.keys()),
+	}
+
+	if withCommits {
+		commits := make(map[string]object.Object, len(result.commits))
+		for key, ticketCommits := range result.commits {
+			elements := make([]object.Object, 0, len(ticketCommits))
+			for _, c := range ticketCommits {
+				elements = append(elements, &object.Commit{Commit: c})
+			}
+
+			commits[key] = &object.Array{Elements: elements}
+		}
+
+		pairs["commits"] = object.NewStringHash(commits)
+	}
+
+	return object.NewStringHash(pairs), nil
+}
+
+// scanner creates the ticketScanner of the builtin using its 'trackers', 'ignore', 'allow', 'uppercase',
+// 'stripZeros', 'aliases', 'notes', 'notesRef', 'trailers' and 'branches' options. The ticket regex (the 'tickets'
+// variable) restricts the projects of the Jira keys.
+func (ra *rangeArgs) scanner(ticketRegex string) (*ticketScanner, *object.Error) {
+	scanner := &ticketScanner{}
+
+	trackers, err := optionStrings(ra.opts, "trackers")
+	if err != nil {
+		return nil, ra.optionError(err)
+	}
+
+	filter := &issue.Filter{}
+	if filter.Ignore, err = optionStrings(ra.opts, "ignore"); err != nil {
+		return nil, ra.optionError(err)
+	}
+
+	if filter.Allow, err = optionStrings(ra.opts, "allow"); err != nil {
+		return nil, ra.optionError(err)
+	}
+
+	normalization, err := ra.normalization()
+	if err != nil {
+		return nil, ra.optionError(err)
+	}
+
+	if scanner.matcher, err = issue.NewMatcher(ticketRegex, trackers, filter, normalization); err != nil {
+		return nil, ra.optionError(err)
+	}
+
+	if scanner.trailerKeys, err = optionStrings(ra.opts, "trailers"); err != nil {
+		return nil, ra.optionError(err)
+	}
+
+	if scanner.branches, err = optionBool(ra.opts, "branches", true); err != nil {
+		return nil, ra.optionError(err)
+	}
+
+	useNotes, err := optionBool(ra.opts, "notes", true)
+	if err != nil {
+		return nil, ra.optionError(err)
+	}
+
+	notesRef, err := optionString(ra.opts, "notesRef", scl.DefaultNotesRef)
+	if err != nil {
+		return nil, ra.optionError(err)
+	}
+
+	if useNotes {
+		if scanner.notes, err = ra.repo.Repo.Notes(notesRef); err != nil {
+			return nil, newError("%s while executing '%s'", err.Error(), ra.builtin)
+		}
+	}
+
+	return scanner, nil
+}
+
+// normalization reads the 'uppercase', 'stripZeros' and 'aliases' options (see issue.Normalization)
+func (ra *rangeArgs) normalization() (*issue.Normalization, error) {
+	uppercase, err := optionBool(ra.opts, "uppercase", true)
+	if err != nil {
+		return nil, err
+	}
+
+	normalization := &issue.Normalization{KeepCase: !uppercase}
+	if normalization.StripZeros, err = optionBool(ra.opts, "stripZeros", false); err != nil {
+		return nil, err
+	}
+
+	if normalization.Aliases, err = optionStringMap(ra.opts, "aliases"); err != nil {
+		return nil, err
+	}
+
+	return normalization, nil
+}
+
+// scanResult contains the issues found in some commits, without duplicates, along with the sources and the commits
+// each issue was found in (indexed by key)
+type scanResult struct {
+	issues  []issue.Issue
+	sources map[string][]string
+	commits map[string][]*gitobject.Commit
+}
+
+// keys returns the keys of the issues
+func (result *scanResult) keys() []string {
+	keys := make([]string, 0, len(result.issues))
+	for _, found := range result.issues {
+		keys = append(keys, found.Key)
+	}
+
+	return keys
+}
+
+// scan returns the tickets found in the commits, without duplicates
+func (scanner *ticketScanner) scan(commits []*gitobject.Commit) ([]string, error) {
+	result, err := scanner.scanIssues(commits)
+	if err != nil {
+		return nil, err
+	}
+
+	return result.keys(), nil
+}
+
+// scanIssues returns the issues found in the commits with their sources and commits
+func (scanner *ticketScanner) scanIssues(commits []*gitobject.Commit) (*scanResult, error) {
+	result := &scanResult{
+		issues:  make([]issue.Issue, 0),
+		sources: make(map[string][]string),
+		commits: make(map[string][]*gitobject.Commit),
+	}
+
+	for _, c := range commits {
+		texts, err := scanner.texts(c)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, text := range texts {
+			for _, found := range scanner.matcher.Find(text.text) {
+				if _, ok := result.sources[found.Key]; !ok {
+					result.issues = append(result.issues, found)
+				}
+
+				result.sources[found.Key] = unique(append(result.sources[found.Key], text.source))
+
+				ticketCommits := result.commits[found.Key]
+				if len(ticketCommits) == 0 || ticketCommits[len(ticketCommits)-1] != c {
+					result.commits[found.Key] = append(ticketCommits, c)
+				}
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// texts returns the texts of the commit that are scanned, with their source
+func (scanner *ticketScanner) texts(c *gitobject.Commit) ([]sourceText, error) {
+	texts := make([]sourceText, 0, 3)
+
+	var branches []string
+	if scanner.branches {
+		branches = scl.MergedBranches(c.Message)
+	}
+
+	switch {
+	case len(scanner.trailerKeys) > 0:
+		texts = append(texts, sourceText{source: sourceTrailer, text: scanner.trailerValues(c)})
+	case len(branches) > 0:
+		// The names of the branches are scanned apart, the rest of the subject of a merge commit may still contain
+		// the number of the pull request (ex: 'Merge pull request #42 from org/feature/ABC-1')
+		subject, body := scl.SplitMessage(c.Message)
+		for _, branch := range branches {
+			subject = strings.Replace(subject, branch, "", 1)
+		}
+
+		texts = append(texts, sourceText{source: sourceMessage, text: subject + "\n" + body})
+	default:
+		texts = append(texts, sourceText{source: sourceMessage, text: c.Message})
+	}
+
+	if len(branches) > 0 {
+		texts = append(texts, sourceText{source: sourceBranch, text: strings.Join(branches, "\n")})
+	}
+
+	if scanner.notes != nil {
+		note, found, err := scanner.notes.Note(c.Hash)
+		if err != nil {
+			return nil, err
+		}
+
+		if found {
+			texts = append(texts, sourceText{source: sourceNote, text: note})
+		}
+	}
+
+	return texts, nil
+}
+
+// trailerValues returns the values of the trailers of the commit having one of the keys of the scanner
+func (scanner *ticketScanner) trailerValues(c *gitobject.Commit) string {
+	values := make([]string, 0)
+	for _, trailer := range scl.ParseTrailers(c.Message) {
+		for _, key := range scanner.trailerKeys {
+			if strings.EqualFold(trailer.Key, key) {
+				values = append(values, trailer.Value)
+			}
+		}
+	}
+
+	return strings.Join(values, "\n")
+}
+
+// scanApart returns the tickets found in the commits set apart from a selection (ex: the reverted commits), except
+// the tickets that are also referenced by the selected commits.
+func (scanner *ticketScanner) scanApart(apart, selected []*gitobject.Commit) ([]string, error) {
+	selectedTickets, err := scanner.scan(selected)
+	if err != nil {
+		return nil, err
+	}
+
+	shipped := make(map[string]bool)
+	for _, ticket := range selectedTickets {
+		shipped[ticket] = true
+	}
+
+	apartTickets, err := scanner.scan(apart)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]string, 0)
+	for _, ticket := range apartTickets {
+		if !shipped[ticket] {
+			result = append(result, ticket)
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,76 @@
+package object
+
+import (
+	"github.com/TurnsCoffeeIntoScripts/git-log-issue-finder/pkg/scl"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"time"
+)
+
+// shortHashLength is the number of characters of the abbreviated commit hash (same as git's default)
+const shortHashLength = 7
+
+// Commit is a wrapper for the interpreter of the *object.Commit (go-git) object
+// Its fields are accessible with the index operator using their name (ex: commit["subject"]), see Field.
+type Commit struct {
+	Commit *object.Commit
+}
+
+// Type returns CommitObj (COMMIT)
+func (c *Commit) Type() Type {
+	return CommitObj
+}
+
+// Inspect returns the abbreviated hash followed by the subject of the commit (like 'git log --oneline')
+func (c *Commit) Inspect() string {
+	subject, _ := scl.SplitMessage(c.Commit.Message)
+	return c.Commit.Hash.String()[:shortHashLength] + " " + subject
+}
+
+// ResolvedCommit returns the commit itself
+func (c *Commit) ResolvedCommit() *object.Commit {
+	return c.Commit
+}
+
+// Field returns the value of one of the fields of the commit:
+//	- hash, shortHash
+//	- author, authorEmail, authorDate
+//	- committer, committerEmail, committerDate
+//	- subject, body, message
+//	- parents (array of hashes)
+// The dates are formatted using RFC 3339. The second value is false if the field does not exist.
+func (c *Commit) Field(name string) (Object, bool) {
+	subject, body := scl.SplitMessage(c.Commit.Message)
+
+	switch name {
+	case "hash":
+		return &String{Value: c.Commit.Hash.String()}, true
+	case "shortHash":
+		return &String{Value: c.Commit.Hash.String()[:shortHashLength]}, true
+	case "author":
+		return &String{Value: c.Commit.Author.Name}, true
+	case "authorEmail":
+		return &String{Value: c.Commit.Author.Email}, true
+	case "authorDate":
+		return &String{Value: c.Commit.Author.When.Format(time.RFC3339)}, true
+	case "committer":
+		return &String{Value: c.Commit.Committer.Name}, true
+	case "committerEmail":
+		return &String{Value: c.Commit.Committer.Email}, true
+	case "committerDate":
+		return &String{Value: c.Commit.Committer.When.Format(time.RFC3339)}, true
+	case "subject":
+		return &String{Value: subject}, true
+	case "body":
+		return &String{Value: body}, true
+	case "message":
+		return &String{Value: c.Commit.Message}, true
+	case "parents":
+		parents := make([]Object, 0, len(c.Commit.ParentHashes))
+		for _, hash := range c.Commit.ParentHashes {
+			parents = append(parents, &String{Value: hash.String()})
+		}
+		return &Array{Elements: parents}, true
+	default:
+		return nil, false
+	}
+}
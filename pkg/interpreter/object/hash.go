@@ -78,3 +78,15 @@ func (h *Hash) Inspect() string {
 	return out.String()
 
 }
+
+// NewStringHash creates a hash from a native map where all the keys are strings
+func NewStringHash(values map[string]Object) *Hash {
+	pairs := make(map[HashKey]HashPair, len(values))
+
+	for key, value := range values {
+		hashKey := &String{Value: key}
+		pairs[hashKey.HashKey()] = HashPair{Key: hashKey, Value: value}
+	}
+
+	return &Hash{Pairs: pairs}
+}
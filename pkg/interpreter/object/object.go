@@ -3,6 +3,7 @@
 //	- Array
 //	- Boolean
 //	- Builtin (function)
+//	- Commit (a go-git commit object)
 //	- Environment (for variable definition and such)
 //	- Error (for parser handling)
 //	- Function (user defined, not builtins)
@@ -10,11 +11,16 @@
 //	- Integer
 //	- Null
 //	- Repo (a go-git git repository)
+//	- Revision (a commit designated by a git revision)
 // 	- Return
 //	- String
-//	- Tag (a go-git tag object)
+//	- Tag (an annotated or lightweight git tag)
 package object
 
+import (
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
 // Definition of constants for "Type"
 const (
 	IntegerObj     = "INTEGER"
@@ -29,6 +35,8 @@ const (
 	HashObj        = "HASH"
 	RepoObj        = "REPO"
 	TagObj         = "TAG"
+	RevisionObj    = "REVISION"
+	CommitObj      = "COMMIT"
 )
 
 // Type refers to the constant which defines an internal type
@@ -39,3 +47,10 @@ type Object interface {
 	Type() Type
 	Inspect() string
 }
+
+// Committish is the interface implemented by the objects that designate a commit (Tag, Revision and Commit).
+// The builtins working on the git history accept any of them.
+type Committish interface {
+	Object
+	ResolvedCommit() *object.Commit
+}
@@ -0,0 +1,27 @@
+package object
+
+import (
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// Revision is a wrapper for the interpreter of a commit designated by a git revision (branch, commit hash, HEAD,
+// 'main~3', 'v1.2.0^{}', ...)
+type Revision struct {
+	Value  Object
+	Commit *object.Commit
+}
+
+// Type returns RevisionObj (REVISION)
+func (r *Revision) Type() Type {
+	return RevisionObj
+}
+
+// Inspect the value which is the revision as it was specified
+func (r *Revision) Inspect() string {
+	return r.Value.Inspect()
+}
+
+// ResolvedCommit returns the commit designated by the revision
+func (r *Revision) ResolvedCommit() *object.Commit {
+	return r.Commit
+}
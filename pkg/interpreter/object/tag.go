@@ -1,13 +1,14 @@
 package object
 
 import (
+	"github.com/TurnsCoffeeIntoScripts/git-log-issue-finder/pkg/scl"
 	"github.com/go-git/go-git/v5/plumbing/object"
 )
 
-// Tag is a wrapper for the interpreter of the *object.Tag (go-git) object
+// Tag is a wrapper for the interpreter of the *scl.GlifTag object (either an annotated or a lightweight git tag)
 type Tag struct {
 	Value Object
-	Tag   *object.Tag
+	Tag   *scl.GlifTag
 }
 
 // Type returns TagObj (TAG)
@@ -19,3 +20,8 @@ func (t *Tag) Type() Type {
 func (t *Tag) Inspect() string {
 	return t.Value.Inspect()
 }
+
+// ResolvedCommit returns the commit targeted by the tag
+func (t *Tag) ResolvedCommit() *object.Commit {
+	return t.Tag.Commit
+}
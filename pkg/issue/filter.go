@@ -0,0 +1,76 @@
+package issue
+
+import (
+	"io/ioutil"
+	"strings"
+)
+
+// Denylist contains the prefixes of common technical tokens that look like Jira keys (ex: UTF-8, SHA-256, ISO-8601,
+// x86-64, CVE-2021). It only applies when the keys of every project are searched (see AnyProject) without an allow
+// list (see Filter.Allow): a project listed explicitly, in the projects or in the allow list, is always matched.
+var Denylist = []string{
+	"AES", "ARM", "CVE", "CWE", "ECMA", "ES", "GPL", "HTTP", "IEC", "IEEE", "ISO", "LGPL", "MD", "PEP", "RFC",
+	"RSA", "SHA", "TLS", "UCS", "UTF", "WIN", "X86",
+}
+
+// Filter drops the issues that are false positives
+type Filter struct {
+	// Ignore contains the keys (ex: ABC-12) and the projects (ex: ABC, org/repo, see Issue.Project) that are ignored
+	Ignore []string
+
+	// Allow contains the known Jira project keys. When it isn't empty, the Jira keys of the other projects are ignored.
+	Allow []string
+
+	denied map[string]bool
+	keys   map[string]bool
+}
+
+// withDenylist returns a copy of the filter indexing its lists (case insensitive), including the Denylist if needed.
+// The Denylist isn't used with an allow list, which already drops the keys of the unknown projects.
+func (f *Filter) withDenylist(useDenylist bool) *Filter {
+	indexed := &Filter{Ignore: f.Ignore, Allow: f.Allow, denied: make(map[string]bool), keys: make(map[string]bool)}
+
+	for _, ignored := range f.Ignore {
+		indexed.denied[strings.ToUpper(strings.TrimSpace(ignored))] = true
+	}
+
+	if useDenylist && len(f.Allow) == 0 {
+		for _, denied := range Denylist {
+			indexed.denied[denied] = true
+		}
+	}
+
+	for _, project := range f.Allow {
+		indexed.keys[strings.ToUpper(strings.TrimSpace(project))] = true
+	}
+
+	return indexed
+}
+
+// keep returns true when the issue is not a false positive
+func (f *Filter) keep(i Issue) bool {
+	project := strings.ToUpper(i.Project())
+	if f.denied[strings.ToUpper(i.Key)] || (project != "" && f.denied[project]) {
+		return false
+	}
+
+	return i.Tracker != Jira || len(f.keys) == 0 || f.keys[project]
+}
+
+// ReadList reads a list from a file: one value per line, the empty lines and the comments (starting with '#') are
+// ignored (ex: the known project keys)
+func ReadList(path string) ([]string, error) {
+	buffer, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make([]string, 0)
+	for _, line := range strings.Split(string(buffer), "\n") {
+		if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "#") {
+			values = append(values, line)
+		}
+	}
+
+	return values, nil
+}
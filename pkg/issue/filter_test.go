@@ -0,0 +1,64 @@
+package issue
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFilter(t *testing.T) {
+	text := "ABC-1 uses UTF-8, SHA-256, ISO-8601 and x86-64 (CVE-2021-44228) XYZ-2 #3 org/repo#4 legacy/repo#5"
+	trackers := []string{"jira", "github"}
+
+	tests := []struct {
+		projects string
+		filter   *Filter
+		expected string
+	}{
+		{"*", nil, "[{ABC-1 jira} {XYZ-2 jira} {#3 github} {org/repo#4 github} {legacy/repo#5 github}]"},
+		{"ABC,UTF,SHA", nil, "[{ABC-1 jira} {UTF-8 jira} {SHA-256 jira} {#3 github} {org/repo#4 github} {legacy/repo#5 github}]"},
+		{"*", &Filter{Ignore: []string{"xyz", "#3", "legacy/repo", "abc-2"}}, "[{ABC-1 jira} {org/repo#4 github}]"},
+		{"*", &Filter{Allow: []string{" abc "}}, "[{ABC-1 jira} {#3 github} {org/repo#4 github} {legacy/repo#5 github}]"},
+		// A project of the allow list is never denied
+		{"*", &Filter{Allow: []string{"UTF", "XYZ"}}, "[{UTF-8 jira} {XYZ-2 jira} {#3 github} {org/repo#4 github} {legacy/repo#5 github}]"},
+	}
+
+	for _, tt := range tests {
+		m, err := NewMatcher(tt.projects, trackers, tt.filter, nil)
+		if err != nil {
+			t.Fatalf("NewMatcher returned an error: %v", err)
+		}
+
+		if got := fmt.Sprint(m.Find(text)); got != tt.expected {
+			t.Errorf("wrong issues for %q %+v. got=%s, want=%s", tt.projects, tt.filter, got, tt.expected)
+		}
+	}
+}
+
+func TestReadList(t *testing.T) {
+	dir, err := ioutil.TempDir("", "glif-issue-")
+	if err != nil {
+		t.Fatalf("unable to create temporary directory: %v", err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	path := filepath.Join(dir, "projects.txt")
+	if err := ioutil.WriteFile(path, []byte("# Known projects\nABC\n\n  XYZ  \r\n#OLD\n"), 0644); err != nil {
+		t.Fatalf("unable to write %s: %v", path, err)
+	}
+
+	values, err := ReadList(path)
+	if err != nil {
+		t.Fatalf("ReadList returned an error: %v", err)
+	}
+
+	if fmt.Sprint(values) != "[ABC XYZ]" {
+		t.Errorf("wrong values. got=%q", values)
+	}
+
+	if _, err := ReadList(filepath.Join(dir, "missing.txt")); err == nil {
+		t.Errorf("ReadList did not return an error for a missing file")
+	}
+}
@@ -0,0 +1,276 @@
+// Package issue finds the keys of the issues referenced in a text (ex: a commit message).
+//
+// The keys are described by named providers, one for each supported issue tracker:
+//	- jira: PROJECT-123, restricted to some project keys when specified
+//	- github: #123 and org/repo#123
+//	- gitlab: !45 and group/project!45 (merge requests)
+//	- azure: AB#678 (Azure Boards)
+//
+// A Matcher combines the providers of several trackers and records the tracker of each issue it finds. A key is only
+// matched as a whole word: it can't be preceded or followed by a letter or a digit (ex: 'preABC-12post' is ignored).
+// The keys are normalized (see Normalization) and the false positives are dropped by a Filter.
+package issue
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Names of the built-in issue trackers
+const (
+	Jira   = "jira"
+	GitHub = "github"
+	GitLab = "gitlab"
+	Azure  = "azure"
+)
+
+// AnyProject is the list of project keys matching every Jira project
+const AnyProject = "*"
+
+// Issue is the key of an issue found in a text and the tracker it belongs to
+type Issue struct {
+	Key     string
+	Tracker string
+}
+
+// Provider describes the keys of the issues of a tracker
+type Provider struct {
+	Name string
+
+	// Pattern returns the regex matching the keys. The projects are the comma separated project keys given by the
+	// user (ex: the 'tickets' variable), AnyProject when the keys of every project are searched.
+	Pattern func(projects string) (string, error)
+}
+
+// repository matches the optional 'org/repo' prefix of the GitHub and GitLab references
+const repository = `(?:[a-zA-Z0-9_.-]+/[a-zA-Z0-9_.-]+)?`
+
+// providers are the built-in providers. The order is the precedence of the providers when their keys overlap
+// (ex: 'AB#678' is an Azure Boards key and not the GitHub issue '#678').
+var providers = []*Provider{
+	{Name: Azure, Pattern: func(string) (string, error) { return `AB#[0-9]+`, nil }},
+	{Name: GitLab, Pattern: func(string) (string, error) { return repository + `![0-9]+`, nil }},
+	{Name: GitHub, Pattern: func(string) (string, error) { return repository + `#[0-9]+`, nil }},
+	{Name: Jira, Pattern: jiraPattern},
+}
+
+// jiraPattern matches the keys of the projects. A project key starts with a letter. Each of the comma separated
+// projects can be a regex (ex: 'AB[CD]'), it must be valid on its own (see SplitProjects).
+func jiraPattern(projects string) (string, error) {
+	if projects == AnyProject {
+		return `[a-zA-Z][a-zA-Z0-9]*-[0-9]+`, nil
+	}
+
+	alternatives := make([]string, 0)
+	for _, project := range SplitProjects(projects) {
+		if _, err := regexp.Compile(project); err != nil {
+			return "", fmt.Errorf("invalid ticket pattern '%s' in '%s': %v", project, projects, err)
+		}
+
+		alternatives = append(alternatives, "(?:"+project+")")
+	}
+
+	if len(alternatives) == 0 {
+		return "", fmt.Errorf("invalid ticket pattern '%s': no project specified", projects)
+	}
+
+	return "(?:" + strings.Join(alternatives, "|") + ")-[0-9]+", nil
+}
+
+// SplitProjects splits the comma separated projects, ignoring the empty values. A comma that is escaped ('\,') or
+// that is part of a group, a repetition or a character class of a regex (ex: 'A{2,3}') doesn't separate two projects.
+func SplitProjects(projects string) []string {
+	values := make([]string, 0)
+	var current strings.Builder
+	depth, inClass, escaped := 0, false, false
+
+	add := func() {
+		if value := strings.TrimSpace(current.String()); value != "" {
+			values = append(values, value)
+		}
+		current.Reset()
+	}
+
+	for _, r := range projects {
+		switch {
+		case escaped:
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case inClass:
+			inClass = r != ']'
+		case r == '[':
+			inClass = true
+		case r == '(' || r == '{':
+			depth++
+		case (r == ')' || r == '}') && depth > 0:
+			depth--
+		case r == ',' && depth == 0:
+			add()
+			continue
+		}
+
+		current.WriteRune(r)
+	}
+
+	add()
+	return values
+}
+
+// Trackers returns the names of the built-in trackers, sorted alphabetically
+func Trackers() []string {
+	names := make([]string, 0, len(providers))
+	for _, provider := range providers {
+		names = append(names, provider.Name)
+	}
+
+	sort.Strings(names)
+	return names
+}
+
+// Project returns the project of the issue: the project key of a Jira or an Azure Boards issue, the repository of a
+// GitHub or a GitLab issue (empty for '#123').
+func (i Issue) Project() string {
+	if index := strings.LastIndexAny(i.Key, "-#!"); index >= 0 {
+		return i.Key[:index]
+	}
+
+	return ""
+}
+
+// Matcher finds the issues of the selected trackers
+type Matcher struct {
+	regex         *regexp.Regexp
+	groups        []int    // Index of the group of each tracker in the regex
+	trackers      []string // Tracker of each group
+	filter        *Filter
+	normalization *Normalization
+}
+
+// NewMatcher creates the Matcher of the named trackers (only Jira when none are specified). The projects restrict
+// the Jira keys (see Provider.Pattern), they are case insensitive unless the normalization keeps the case of the
+// keys. The filter and the normalization are optional.
+func NewMatcher(projects string, trackers []string, filter *Filter, normalization *Normalization) (*Matcher, error) {
+	if len(trackers) == 0 {
+		trackers = []string{Jira}
+	}
+
+	selected := make(map[string]bool)
+	for _, tracker := range trackers {
+		name := strings.ToLower(strings.TrimSpace(tracker))
+		if Lookup(name) == nil {
+			return nil, fmt.Errorf("unknown issue tracker '%s' (expected one of %s)", tracker,
+				strings.Join(Trackers(), ", "))
+		}
+
+		selected[name] = true
+	}
+
+	if filter == nil {
+		filter = &Filter{}
+	}
+
+	if normalization == nil {
+		normalization = &Normalization{}
+	}
+
+	m := &Matcher{filter: filter.withDenylist(projects == AnyProject), normalization: normalization.withAliases()}
+	patterns := make([]string, 0, len(selected))
+	for _, provider := range providers {
+		if !selected[provider.Name] {
+			continue
+		}
+
+		pattern, err := provider.Pattern(projects)
+		if err != nil {
+			return nil, err
+		}
+
+		// The keys are converted to upper case, so the projects listed explicitly match the keys written in lower case
+		if provider.Name == Jira && projects != AnyProject && !normalization.KeepCase {
+			pattern = "(?i:" + pattern + ")"
+		}
+
+		// The groups are named since the projects may contain groups as well
+		patterns = append(patterns, "(?P<"+provider.Name+">"+pattern+")")
+		m.trackers = append(m.trackers, provider.Name)
+	}
+
+	regex, err := regexp.Compile(strings.Join(patterns, "|"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid ticket pattern '%s': %v", projects, err)
+	}
+
+	m.regex = regex
+	for _, tracker := range m.trackers {
+		for index, name := range regex.SubexpNames() {
+			if name == tracker {
+				m.groups = append(m.groups, index)
+			}
+		}
+	}
+
+	return m, nil
+}
+
+// Lookup returns the built-in provider of the tracker, nil if there is none
+func Lookup(tracker string) *Provider {
+	for _, provider := range providers {
+		if provider.Name == tracker {
+			return provider
+		}
+	}
+
+	return nil
+}
+
+// Find returns the normalized issues found in the text, in order of appearance, except the ones dropped by the filter
+func (m *Matcher) Find(text string) []Issue {
+	issues := make([]Issue, 0)
+	for _, match := range m.regex.FindAllStringSubmatchIndex(text, -1) {
+		for i, tracker := range m.trackers {
+			start, end := match[2*m.groups[i]], match[2*m.groups[i]+1]
+			if start < 0 {
+				continue
+			}
+
+			if !isWord(text, start, end) {
+				break
+			}
+
+			found := m.normalization.apply(Issue{Key: text[start:end], Tracker: tracker})
+			if m.filter.keep(found) {
+				issues = append(issues, found)
+			}
+			break
+		}
+	}
+
+	return issues
+}
+
+// isWord returns true when the text between start and end is neither preceded nor followed by a letter or a digit
+func isWord(text string, start, end int) bool {
+	if before, _ := utf8.DecodeLastRuneInString(text[:start]); start > 0 && isAlphanumeric(before) {
+		return false
+	}
+
+	if after, _ := utf8.DecodeRuneInString(text[end:]); end < len(text) && isAlphanumeric(after) {
+		return false
+	}
+
+	return true
+}
+
+func isAlphanumeric(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// Trackers returns the names of the trackers of the matcher, in order of precedence
+func (m *Matcher) Trackers() []string {
+	return m.trackers
+}
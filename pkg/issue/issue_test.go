@@ -0,0 +1,172 @@
+package issue
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestMatcherFind(t *testing.T) {
+	text := "ABC-1 fixes #12, org/repo#34 and AB#56\n\nSee merge request group/project!78 and !9 (XYZ-2)"
+
+	tests := []struct {
+		projects string
+		trackers []string
+		expected string
+	}{
+		{"*", nil, "[{ABC-1 jira} {XYZ-2 jira}]"},
+		{"XYZ", []string{"jira"}, "[{XYZ-2 jira}]"},
+		{"*", []string{"github"}, "[{#12 github} {org/repo#34 github}]"},
+		{"*", []string{"gitlab"}, "[{group/project!78 gitlab} {!9 gitlab}]"},
+		{"*", []string{"azure"}, "[{AB#56 azure}]"},
+		{"ABC", []string{"GitHub", " azure ", "jira"},
+			"[{ABC-1 jira} {#12 github} {org/repo#34 github} {AB#56 azure}]"},
+		{"*", []string{"jira", "github", "gitlab", "azure"},
+			"[{ABC-1 jira} {#12 github} {org/repo#34 github} {AB#56 azure} {group/project!78 gitlab} {!9 gitlab} {XYZ-2 jira}]"},
+	}
+
+	for _, tt := range tests {
+		m, err := NewMatcher(tt.projects, tt.trackers, nil, nil)
+		if err != nil {
+			t.Fatalf("NewMatcher(%q, %v) returned an error: %v", tt.projects, tt.trackers, err)
+		}
+
+		if got := fmt.Sprint(m.Find(text)); got != tt.expected {
+			t.Errorf("wrong issues for %q %v. got=%s, want=%s", tt.projects, tt.trackers, got, tt.expected)
+		}
+	}
+}
+
+func TestMatcherWholeWords(t *testing.T) {
+	tests := []struct {
+		projects string
+		text     string
+		expected string
+	}{
+		{"*", "preABC-12post ABC-13post ABC-14", "[{ABC-14 jira}]"},
+		{"ABC", "preABC-12 ABC-13_login feature/ABC-14-search (ABC-15)", "[{ABC-13 jira} {ABC-14 jira} {ABC-15 jira}]"},
+		{"*", "2021-10-05 1ABC-2 ABC-3,ABC-4", "[{ABC-3 jira} {ABC-4 jira}]"},
+		{"*", "éABC-1 ABC-2é", "[]"},
+	}
+
+	for _, tt := range tests {
+		m, err := NewMatcher(tt.projects, nil, nil, nil)
+		if err != nil {
+			t.Fatalf("NewMatcher returned an error: %v", err)
+		}
+
+		if got := fmt.Sprint(m.Find(tt.text)); got != tt.expected {
+			t.Errorf("wrong issues in %q. got=%s, want=%s", tt.text, got, tt.expected)
+		}
+	}
+}
+
+func TestIssueProject(t *testing.T) {
+	tests := []struct {
+		key      string
+		expected string
+	}{
+		{"ABC-12", "ABC"},
+		{"AB#678", "AB"},
+		{"#12", ""},
+		{"org/repo#34", "org/repo"},
+		{"group/my-project!45", "group/my-project"},
+	}
+
+	for _, tt := range tests {
+		if got := (Issue{Key: tt.key}).Project(); got != tt.expected {
+			t.Errorf("wrong project of %s. got=%q, want=%q", tt.key, got, tt.expected)
+		}
+	}
+}
+
+func TestNewMatcherUnknownTracker(t *testing.T) {
+	_, err := NewMatcher("*", []string{"jira", "trello"}, nil, nil)
+	if err == nil {
+		t.Fatalf("NewMatcher did not return an error")
+	}
+
+	expected := "unknown issue tracker 'trello' (expected one of azure, github, gitlab, jira)"
+	if err.Error() != expected {
+		t.Errorf("wrong error. got=%q, want=%q", err.Error(), expected)
+	}
+}
+
+func TestTrackers(t *testing.T) {
+	if got := fmt.Sprint(Trackers()); got != "[azure github gitlab jira]" {
+		t.Errorf("wrong trackers. got=%s", got)
+	}
+
+	m, err := NewMatcher("*", []string{"jira", "azure"}, nil, nil)
+	if err != nil {
+		t.Fatalf("NewMatcher returned an error: %v", err)
+	}
+
+	if got := fmt.Sprint(m.Trackers()); got != "[azure jira]" {
+		t.Errorf("wrong trackers of the matcher. got=%s", got)
+	}
+}
+
+func TestSplitProjects(t *testing.T) {
+	tests := []struct {
+		projects string
+		expected []string
+	}{
+		{"ABC,XYZ", []string{"ABC", "XYZ"}},
+		{" ABC , ,XYZ,", []string{"ABC", "XYZ"}},
+		{"A{2,3}B,(C|D),[,;]E,F\\,G", []string{"A{2,3}B", "(C|D)", "[,;]E", "F\\,G"}},
+		{"(A,B", []string{"(A,B"}},
+	}
+
+	for _, tt := range tests {
+		if got := SplitProjects(tt.projects); fmt.Sprintf("%q", got) != fmt.Sprintf("%q", tt.expected) {
+			t.Errorf("SplitProjects(%q) is wrong. got=%q, want=%q", tt.projects, got, tt.expected)
+		}
+	}
+}
+
+func TestMatcherProjectPatterns(t *testing.T) {
+	tests := []struct {
+		projects string
+		text     string
+		expected string
+	}{
+		{"AB[CD],(X)(Y)Z", "ABC-1 ABD-2 ABE-3 XYZ-4", "[{ABC-1 jira} {ABD-2 jira} {XYZ-4 jira}]"},
+		{"A{2,3}", "A-1 AA-2 AAA-3", "[{AA-2 jira} {AAA-3 jira}]"},
+		{"ABC|XYZ", "ABC-1 XYZ-2", "[{ABC-1 jira} {XYZ-2 jira}]"},
+	}
+
+	for _, tt := range tests {
+		m, err := NewMatcher(tt.projects, []string{"jira", "github"}, nil, nil)
+		if err != nil {
+			t.Fatalf("NewMatcher(%q) returned an error: %v", tt.projects, err)
+		}
+
+		if got := fmt.Sprint(m.Find(tt.text)); got != tt.expected {
+			t.Errorf("wrong issues for %q. got=%s, want=%s", tt.projects, got, tt.expected)
+		}
+	}
+}
+
+func TestNewMatcherInvalidPattern(t *testing.T) {
+	tests := []struct {
+		projects string
+		expected string
+	}{
+		{"ABC,AB[C", "invalid ticket pattern 'AB[C' in 'ABC,AB[C': error parsing regexp: missing closing ]: `[C`"},
+		{"ABC)|(.*", "invalid ticket pattern 'ABC)|(.*' in 'ABC)|(.*': error parsing regexp: unexpected ): `ABC)|(.*`"},
+		{"ABC,*", "invalid ticket pattern '*' in 'ABC,*': error parsing regexp: missing argument to repetition operator: `*`"},
+		{" , ", "invalid ticket pattern ' , ': no project specified"},
+	}
+
+	for _, tt := range tests {
+		_, err := NewMatcher(tt.projects, nil, nil, nil)
+		if err == nil {
+			t.Errorf("NewMatcher(%q) did not return an error", tt.projects)
+			continue
+		}
+
+		if err.Error() != tt.expected {
+			t.Errorf("wrong error. got=%q, want=%q", err.Error(), tt.expected)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package issue
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Normalization rewrites the keys before they are de-duplicated so that 'abc-12', 'ABC-12' and 'ABC-012' are the
+// same ticket. Its zero value only converts the Jira keys to upper case.
+type Normalization struct {
+	// KeepCase keeps the Jira keys as they were written instead of converting them to upper case
+	KeepCase bool
+
+	// StripZeros removes the leading zeros of the issue numbers (ex: ABC-012 becomes ABC-12)
+	StripZeros bool
+
+	// Aliases maps the keys of renamed Jira projects (case insensitive) to their new key (ex: OLDKEY-5 becomes
+	// NEWKEY-5 with the alias OLDKEY -> NEWKEY)
+	Aliases map[string]string
+}
+
+// withAliases returns a copy of the normalization with the aliases indexed by upper case key
+func (n *Normalization) withAliases() *Normalization {
+	indexed := &Normalization{KeepCase: n.KeepCase, StripZeros: n.StripZeros, Aliases: make(map[string]string)}
+	for project, alias := range n.Aliases {
+		indexed.Aliases[strings.ToUpper(strings.TrimSpace(project))] = strings.TrimSpace(alias)
+	}
+
+	return indexed
+}
+
+// apply returns the normalized issue
+func (n *Normalization) apply(i Issue) Issue {
+	index := strings.LastIndexAny(i.Key, "-#!")
+	project, separator, number := i.Key[:index], i.Key[index:index+1], i.Key[index+1:]
+
+	if i.Tracker == Jira {
+		if alias, ok := n.Aliases[strings.ToUpper(project)]; ok {
+			project = alias
+		}
+
+		if !n.KeepCase {
+			project = strings.ToUpper(project)
+		}
+	}
+
+	if n.StripZeros {
+		if number = strings.TrimLeft(number, "0"); number == "" {
+			number = "0"
+		}
+	}
+
+	return Issue{Key: project + separator + number, Tracker: i.Tracker}
+}
+
+// ReadAliases reads the aliases of the renamed projects from a file: one 'OLDKEY=NEWKEY' alias per line, the empty
+// lines and the comments (starting with '#') are ignored
+func ReadAliases(path string) (map[string]string, error) {
+	lines, err := ReadList(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseAliases(lines, path)
+}
+
+// ParseAliases parses 'OLDKEY=NEWKEY' aliases, the origin (ex: the file) is only used in the error messages
+func ParseAliases(values []string, origin string) (map[string]string, error) {
+	aliases := make(map[string]string, len(values))
+	for _, value := range values {
+		parts := strings.SplitN(value, "=", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" || strings.TrimSpace(parts[1]) == "" {
+			return nil, fmt.Errorf("invalid alias '%s' in %s: expected OLDKEY=NEWKEY", value, origin)
+		}
+
+		aliases[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+	}
+
+	return aliases, nil
+}
@@ -0,0 +1,114 @@
+package issue
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNormalization(t *testing.T) {
+	text := "abc-12 ABC-12 ABC-012 Abc-0 oldkey-5 OLDKEY-05 #007 org/Repo#08 AB#0678 KEEP-1"
+	trackers := []string{"jira", "github", "azure"}
+
+	tests := []struct {
+		normalization *Normalization
+		expected      string
+	}{
+		{nil, "[ABC-12 ABC-12 ABC-012 ABC-0 OLDKEY-5 OLDKEY-05 #007 org/Repo#08 AB#0678 KEEP-1]"},
+		{&Normalization{KeepCase: true}, "[abc-12 ABC-12 ABC-012 Abc-0 oldkey-5 OLDKEY-05 #007 org/Repo#08 AB#0678 KEEP-1]"},
+		{&Normalization{StripZeros: true}, "[ABC-12 ABC-12 ABC-12 ABC-0 OLDKEY-5 OLDKEY-5 #7 org/Repo#8 AB#678 KEEP-1]"},
+		{&Normalization{Aliases: map[string]string{" OldKey ": "NEWKEY", "ABC": "xyz"}},
+			"[XYZ-12 XYZ-12 XYZ-012 XYZ-0 NEWKEY-5 NEWKEY-05 #007 org/Repo#08 AB#0678 KEEP-1]"},
+		{&Normalization{KeepCase: true, StripZeros: true, Aliases: map[string]string{"oldkey": "NewKey"}},
+			"[abc-12 ABC-12 ABC-12 Abc-0 NewKey-5 NewKey-5 #7 org/Repo#8 AB#678 KEEP-1]"},
+	}
+
+	for _, tt := range tests {
+		m, err := NewMatcher(AnyProject, trackers, nil, tt.normalization)
+		if err != nil {
+			t.Fatalf("NewMatcher returned an error: %v", err)
+		}
+
+		keys := make([]string, 0)
+		for _, found := range m.Find(text) {
+			keys = append(keys, found.Key)
+		}
+
+		if got := fmt.Sprint(keys); got != tt.expected {
+			t.Errorf("wrong keys with %+v. got=%s, want=%s", tt.normalization, got, tt.expected)
+		}
+	}
+}
+
+func TestNormalizationExplicitProjects(t *testing.T) {
+	tests := []struct {
+		normalization *Normalization
+		expected      string
+	}{
+		{nil, "[{ABC-12 jira} {ABC-13 jira} {XY-2 jira}]"},
+		{&Normalization{KeepCase: true}, "[{ABC-13 jira}]"},
+	}
+
+	for _, tt := range tests {
+		m, err := NewMatcher("ABC,X[YZ]", nil, nil, tt.normalization)
+		if err != nil {
+			t.Fatalf("NewMatcher returned an error: %v", err)
+		}
+
+		if got := fmt.Sprint(m.Find("abc-12 ABC-13 xy-2 other-3")); got != tt.expected {
+			t.Errorf("wrong issues with %+v. got=%s, want=%s", tt.normalization, got, tt.expected)
+		}
+	}
+}
+
+func TestNormalizationBeforeFilter(t *testing.T) {
+	filter := &Filter{Ignore: []string{"NEWKEY-5"}, Allow: []string{"NEWKEY", "ABC"}}
+	normalization := &Normalization{StripZeros: true, Aliases: map[string]string{"OLDKEY": "NEWKEY"}}
+
+	m, err := NewMatcher(AnyProject, nil, filter, normalization)
+	if err != nil {
+		t.Fatalf("NewMatcher returned an error: %v", err)
+	}
+
+	if got := fmt.Sprint(m.Find("oldkey-005 OLDKEY-6 abc-01 XYZ-1")); got != "[{NEWKEY-6 jira} {ABC-1 jira}]" {
+		t.Errorf("wrong issues. got=%s", got)
+	}
+}
+
+func TestReadAliases(t *testing.T) {
+	dir, err := ioutil.TempDir("", "glif-issue-")
+	if err != nil {
+		t.Fatalf("unable to create temporary directory: %v", err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	path := filepath.Join(dir, "aliases.txt")
+	if err := ioutil.WriteFile(path, []byte("# Renamed projects\nOLDKEY = NEWKEY\n\nLEGACY=ABC\n"), 0644); err != nil {
+		t.Fatalf("unable to write %s: %v", path, err)
+	}
+
+	aliases, err := ReadAliases(path)
+	if err != nil {
+		t.Fatalf("ReadAliases returned an error: %v", err)
+	}
+
+	if fmt.Sprint(aliases) != "map[LEGACY:ABC OLDKEY:NEWKEY]" {
+		t.Errorf("wrong aliases. got=%v", aliases)
+	}
+
+	invalid := []string{"OLDKEY", "=NEWKEY", "OLDKEY= "}
+	for _, value := range invalid {
+		_, err := ParseAliases([]string{value}, "aliases.txt")
+		if err == nil {
+			t.Errorf("ParseAliases(%q) did not return an error", value)
+			continue
+		}
+
+		expected := fmt.Sprintf("invalid alias '%s' in aliases.txt: expected OLDKEY=NEWKEY", value)
+		if err.Error() != expected {
+			t.Errorf("wrong error. got=%q, want=%q", err.Error(), expected)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/TurnsCoffeeIntoScripts/git-log-issue-finder/pkg/interpreter/object"
+	"github.com/TurnsCoffeeIntoScripts/git-log-issue-finder/pkg/scl"
+	"io"
+	"path/filepath"
+	"time"
+)
+
+// SchemaVersion is the version of the JSON document. It changes when a field is removed or when its meaning
+// changes, adding an optional field keeps the version.
+const SchemaVersion = 1
+
+// Document is the JSON representation of the result of a script:
+//	- schemaVersion: see SchemaVersion
+//	- repository: the absolute path of the repository, empty when unknown
+//	- from, to: the sides of the range, null when unknown ('from' is also null when the range starts at the root)
+//	- tickets: the tickets of the range, never null
+//	- added, removed: the tickets new in 'to' and only in 'from', present with the 'sides' option of 'diff'
+//	- commits: the commits referencing each ticket, present with the 'commits' option of 'diff'
+type Document struct {
+	SchemaVersion int                 `json:"schemaVersion"`
+	Repository    string              `json:"repository"`
+	From          *Ref                `json:"from"`
+	To            *Ref                `json:"to"`
+	Tickets       []string            `json:"tickets"`
+	Added         *[]string           `json:"added,omitempty"`
+	Removed       *[]string           `json:"removed,omitempty"`
+	Commits       map[string][]Commit `json:"commits,omitempty"`
+}
+
+// Ref is a side of the range: the reference as it was specified (ex: a tag name) and the hash of its commit
+type Ref struct {
+	Ref  string `json:"ref"`
+	Hash string `json:"hash"`
+}
+
+// Commit is a commit referencing a ticket. The date is the author date, formatted using RFC 3339.
+type Commit struct {
+	Hash    string `json:"hash"`
+	Subject string `json:"subject"`
+	Author  string `json:"author"`
+	Date    string `json:"date"`
+}
+
+// JSON prints the result as an indented Document. The result must be the value returned by 'diff': either an array
+// of tickets or one of its hashes (see the 'details' and 'sides' options).
+func JSON(w io.Writer, result object.Object) error {
+	document, err := NewDocument(result)
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(document)
+}
+
+// NewDocument converts the result of a script to a Document (see JSON)
+func NewDocument(result object.Object) (*Document, error) {
+	document := &Document{SchemaVersion: SchemaVersion, Tickets: make([]string, 0)}
+
+	switch result := result.(type) {
+	case *object.Array:
+		document.Tickets = stringValues(result)
+		return document, nil
+	case *object.Hash:
+		tickets, hasTickets := field(result, "tickets").(*object.Array)
+		added, removed, hasSides := sides(result)
+		if !hasTickets && !hasSides {
+			break
+		}
+
+		if hasTickets {
+			document.Tickets = stringValues(tickets)
+		}
+
+		if added != nil {
+			addedTickets := stringValues(added)
+			document.Added = &addedTickets
+		}
+
+		if removed != nil {
+			removedTickets := stringValues(removed)
+			document.Removed = &removedTickets
+		}
+
+		if repo, ok := field(result, "repo").(*object.Repo); ok {
+			path, err := filepath.Abs(repo.Inspect())
+			if err != nil {
+				return nil, fmt.Errorf("unable to resolve the path of the repository: %v", err)
+			}
+
+			document.Repository = path
+		}
+
+		document.From = ref(field(result, "from"))
+		document.To = ref(field(result, "to"))
+
+		if commits, ok := field(result, "commits").(*object.Hash); ok {
+			document.Commits = ticketCommits(commits)
+		}
+
+		return document, nil
+	}
+
+	typeName := "nothing"
+	if result != nil {
+		typeName = string(result.Type())
+	}
+
+	return nil, fmt.Errorf("unsupported result of the script (expected the value returned by 'diff', got %s)", typeName)
+}
+
+// stringValues returns the values of the elements of the array
+func stringValues(array *object.Array) []string {
+	values := make([]string, 0, len(array.Elements))
+	for _, element := range array.Elements {
+		values = append(values, element.Inspect())
+	}
+
+	return values
+}
+
+// ref converts a side of the range, nil if it isn't a commit (ex: NULL)
+func ref(value object.Object) *Ref {
+	committish, ok := value.(object.Committish)
+	if !ok {
+		return nil
+	}
+
+	return &Ref{Ref: committish.Inspect(), Hash: committish.ResolvedCommit().Hash.String()}
+}
+
+// ticketCommits converts the hash of the commits indexed by ticket
+func ticketCommits(hash *object.Hash) map[string][]Commit {
+	commits := make(map[string][]Commit, len(hash.Pairs))
+	for _, pair := range hash.Pairs {
+		array, ok := pair.Value.(*object.Array)
+		if !ok {
+			continue
+		}
+
+		ticket := make([]Commit, 0, len(array.Elements))
+		for _, element := range array.Elements {
+			if c, ok := element.(*object.Commit); ok {
+				subject, _ := scl.SplitMessage(c.Commit.Message)
+				ticket = append(ticket, Commit{
+					Hash:    c.Commit.Hash.String(),
+					Subject: subject,
+					Author:  c.Commit.Author.Name,
+					Date:    c.Commit.Author.When.Format(time.RFC3339),
+				})
+			}
+		}
+
+		commits[pair.Key.Inspect()] = ticket
+	}
+
+	return commits
+}
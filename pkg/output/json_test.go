@@ -0,0 +1,95 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/TurnsCoffeeIntoScripts/git-log-issue-finder/pkg/interpreter/object"
+	"github.com/go-git/go-git/v5/plumbing"
+	gitobject "github.com/go-git/go-git/v5/plumbing/object"
+	"testing"
+	"time"
+)
+
+func TestJSON(t *testing.T) {
+	date := time.Date(2020, time.March, 4, 10, 30, 0, 0, time.UTC)
+	fromCommit := &gitobject.Commit{Hash: plumbing.NewHash("1111111111111111111111111111111111111111")}
+	toCommit := &gitobject.Commit{
+		Hash:    plumbing.NewHash("2222222222222222222222222222222222222222"),
+		Message: "ABC-1 fix the parser\n\nDetails",
+		Author:  gitobject.Signature{Name: "Jane", When: date},
+	}
+
+	from := &object.Revision{Value: &object.String{Value: "1.0.0"}, Commit: fromCommit}
+	to := &object.Revision{Value: &object.String{Value: "HEAD"}, Commit: toCommit}
+
+	tests := []struct {
+		name     string
+		result   object.Object
+		expected string
+	}{
+		{"tickets", stringArray("ABC-1"), `{"schemaVersion":1,"repository":"","from":null,"to":null,"tickets":["ABC-1"]}`},
+		{"details", object.NewStringHash(map[string]object.Object{
+			"from": &object.Null{}, "to": to, "tickets": stringArray(),
+		}), `{"schemaVersion":1,"repository":"","from":null,` +
+			`"to":{"ref":"HEAD","hash":"2222222222222222222222222222222222222222"},"tickets":[]}`},
+		{"sides", object.NewStringHash(map[string]object.Object{
+			"from": from, "to": to, "tickets": stringArray("ABC-1"), "added": stringArray("ABC-1"),
+			"removed": stringArray(),
+		}), `{"schemaVersion":1,"repository":"",` +
+			`"from":{"ref":"1.0.0","hash":"1111111111111111111111111111111111111111"},` +
+			`"to":{"ref":"HEAD","hash":"2222222222222222222222222222222222222222"},` +
+			`"tickets":["ABC-1"],"added":["ABC-1"],"removed":[]}`},
+		{"commits", object.NewStringHash(map[string]object.Object{
+			"from": from, "to": to, "tickets": stringArray("ABC-1"),
+			"commits": object.NewStringHash(map[string]object.Object{
+				"ABC-1": &object.Array{Elements: []object.Object{&object.Commit{Commit: toCommit}}},
+			}),
+		}), `{"schemaVersion":1,"repository":"",` +
+			`"from":{"ref":"1.0.0","hash":"1111111111111111111111111111111111111111"},` +
+			`"to":{"ref":"HEAD","hash":"2222222222222222222222222222222222222222"},"tickets":["ABC-1"],` +
+			`"commits":{"ABC-1":[{"hash":"2222222222222222222222222222222222222222","subject":"ABC-1 fix the parser",` +
+			`"author":"Jane","date":"2020-03-04T10:30:00Z"}]}}`},
+	}
+
+	for _, tt := range tests {
+		var out bytes.Buffer
+		if err := JSON(&out, tt.result); err != nil {
+			t.Fatalf("%s: JSON returned an error: %v", tt.name, err)
+		}
+
+		var compact bytes.Buffer
+		if err := json.Compact(&compact, out.Bytes()); err != nil {
+			t.Fatalf("%s: invalid JSON: %v", tt.name, err)
+		}
+
+		if compact.String() != tt.expected {
+			t.Errorf("%s: wrong document.\ngot=%s\nwant=%s", tt.name, compact.String(), tt.expected)
+		}
+	}
+}
+
+func TestJSONInvalidResult(t *testing.T) {
+	tests := []struct {
+		name     string
+		result   object.Object
+		expected string
+	}{
+		{"no result", nil, "unsupported result of the script (expected the value returned by 'diff', got nothing)"},
+		{"integer", &object.Integer{Value: 3}, "unsupported result of the script (expected the value returned by 'diff', got INTEGER)"},
+		{"other hash", object.NewStringHash(map[string]object.Object{"name": &object.String{Value: "x"}}),
+			"unsupported result of the script (expected the value returned by 'diff', got HASH)"},
+	}
+
+	for _, tt := range tests {
+		var out bytes.Buffer
+		err := JSON(&out, tt.result)
+		if err == nil {
+			t.Errorf("%s: expected an error", tt.name)
+			continue
+		}
+
+		if err.Error() != tt.expected {
+			t.Errorf("%s: wrong error. got=%q, want=%q", tt.name, err.Error(), tt.expected)
+		}
+	}
+}
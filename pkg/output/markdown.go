@@ -0,0 +1,163 @@
+package output
+
+import (
+	"fmt"
+	"github.com/TurnsCoffeeIntoScripts/git-log-issue-finder/pkg/interpreter/object"
+	"github.com/TurnsCoffeeIntoScripts/git-log-issue-finder/pkg/issue"
+	"github.com/go-git/go-git/v5/plumbing"
+	"io"
+	"sort"
+	"text/template"
+	"time"
+)
+
+// DefaultTemplate is the Markdown template of the release notes: the range, then the tickets grouped by project with
+// the subjects of their commits
+const DefaultTemplate = `# Release notes{{with .To}} {{.Name}}{{if .Tag}} ({{.Date.Format "2006-01-02"}}){{end}}{{end}}
+{{with .From}}
+Changes since {{.Name}}{{if .Tag}} ({{.Date.Format "2006-01-02"}}){{end}}.
+{{end}}{{range .Projects}}
+## {{if .Key}}{{.Key}}{{else}}Other{{end}}
+{{range .Tickets}}
+- {{.Key}}{{range .Commits}}
+  - {{.Subject}} ({{.ShortHash}}){{end}}{{end}}
+{{else}}
+No tickets.
+{{end}}`
+
+// ReleaseNotes is the data given to the template of the release notes (see Markdown)
+type ReleaseNotes struct {
+	Repository string
+	From       *Version // nil when unknown or when the range starts at the first commit
+	To         *Version // nil when unknown
+	Tickets    []string
+	Projects   []Project
+}
+
+// Version is a side of the range. The date is the date of the tag (see scl.GlifTag.When) or the committer date of the
+// commit when the side isn't a tag.
+type Version struct {
+	Name string // The short name of the reference (ex: '1.2.0' for 'refs/tags/1.2.0')
+	Ref  string // The reference as it was specified
+	Hash string
+	Date time.Time
+	Tag  bool
+}
+
+// Project contains the tickets of a project (see issue.Issue.Project), the key is empty for the GitHub and GitLab
+// issues of the current repository (ex: '#123')
+type Project struct {
+	Key     string
+	Tickets []Ticket
+}
+
+// Ticket is a ticket and the commits referencing it, which are only known with the 'commits' option of 'diff'
+type Ticket struct {
+	Key     string
+	Commits []Commit
+}
+
+// ShortHash returns the abbreviated hash of the commit
+func (c Commit) ShortHash() string {
+	if len(c.Hash) < 7 {
+		return c.Hash
+	}
+
+	return c.Hash[:7]
+}
+
+// ParseTemplate parses the template of the release notes, DefaultTemplate when the text is empty
+func ParseTemplate(text string) (*template.Template, error) {
+	if text == "" {
+		text = DefaultTemplate
+	}
+
+	tmpl, err := template.New("release-notes").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid release notes template: %v", err)
+	}
+
+	return tmpl, nil
+}
+
+// Markdown prints the release notes of the result using the template (DefaultTemplate when empty). The result must
+// be the value returned by 'diff' (see NewDocument).
+func Markdown(w io.Writer, result object.Object, text string) error {
+	tmpl, err := ParseTemplate(text)
+	if err != nil {
+		return err
+	}
+
+	notes, err := NewReleaseNotes(result)
+	if err != nil {
+		return err
+	}
+
+	if err := tmpl.Execute(w, notes); err != nil {
+		return fmt.Errorf("unable to render the release notes: %v", err)
+	}
+
+	return nil
+}
+
+// NewReleaseNotes converts the result of a script to the data of the release notes. The projects are sorted by key,
+// the tickets of a project keep the order of the result.
+func NewReleaseNotes(result object.Object) (*ReleaseNotes, error) {
+	document, err := NewDocument(result)
+	if err != nil {
+		return nil, err
+	}
+
+	notes := &ReleaseNotes{Repository: document.Repository, Tickets: document.Tickets, Projects: make([]Project, 0)}
+	if hash, ok := result.(*object.Hash); ok {
+		notes.From = version(field(hash, "from"))
+		notes.To = version(field(hash, "to"))
+	}
+
+	indexes := make(map[string]int)
+	for _, key := range document.Tickets {
+		project := issue.Issue{Key: key}.Project()
+		index, ok := indexes[project]
+		if !ok {
+			index = len(notes.Projects)
+			indexes[project] = index
+			notes.Projects = append(notes.Projects, Project{Key: project})
+		}
+
+		ticket := Ticket{Key: key, Commits: document.Commits[key]}
+		notes.Projects[index].Tickets = append(notes.Projects[index].Tickets, ticket)
+	}
+
+	// The issues without a project are listed last
+	sort.SliceStable(notes.Projects, func(i, j int) bool {
+		if notes.Projects[i].Key == "" || notes.Projects[j].Key == "" {
+			return notes.Projects[j].Key == ""
+		}
+
+		return notes.Projects[i].Key < notes.Projects[j].Key
+	})
+
+	return notes, nil
+}
+
+// version converts a side of the range, nil if it isn't a commit (ex: NULL)
+func version(value object.Object) *Version {
+	committish, ok := value.(object.Committish)
+	if !ok {
+		return nil
+	}
+
+	v := &Version{
+		Name: plumbing.ReferenceName(committish.Inspect()).Short(),
+		Ref:  committish.Inspect(),
+		Hash: committish.ResolvedCommit().Hash.String(),
+		Date: committish.ResolvedCommit().Committer.When,
+	}
+
+	if tag, ok := committish.(*object.Tag); ok {
+		v.Date = tag.Tag.When()
+		v.Tag = true
+	}
+
+	return v
+}
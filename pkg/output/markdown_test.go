@@ -0,0 +1,121 @@
+package output
+
+import (
+	"bytes"
+	"github.com/TurnsCoffeeIntoScripts/git-log-issue-finder/pkg/interpreter/object"
+	"github.com/TurnsCoffeeIntoScripts/git-log-issue-finder/pkg/scl"
+	"github.com/go-git/go-git/v5/plumbing"
+	gitobject "github.com/go-git/go-git/v5/plumbing/object"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMarkdown(t *testing.T) {
+	date := time.Date(2020, time.March, 4, 10, 30, 0, 0, time.UTC)
+	fix := &gitobject.Commit{
+		Hash:      plumbing.NewHash("2222222222222222222222222222222222222222"),
+		Message:   "ABC-1 fix the parser",
+		Committer: gitobject.Signature{When: date},
+	}
+	feature := &gitobject.Commit{
+		Hash:    plumbing.NewHash("3333333333333333333333333333333333333333"),
+		Message: "XYZ-2 add the exporter (ABC-1, #4)",
+	}
+
+	commits := func(elements ...*gitobject.Commit) *object.Array {
+		array := &object.Array{}
+		for _, element := range elements {
+			array.Elements = append(array.Elements, &object.Commit{Commit: element})
+		}
+
+		return array
+	}
+
+	result := object.NewStringHash(map[string]object.Object{
+		"from": &object.Null{},
+		"to": &object.Tag{
+			Value: &object.String{Value: "refs/tags/1.2.0"},
+			Tag:   &scl.GlifTag{Name: "refs/tags/1.2.0", Commit: fix},
+		},
+		"tickets": stringArray("XYZ-2", "#4", "ABC-1"),
+		"commits": object.NewStringHash(map[string]object.Object{
+			"XYZ-2": commits(feature),
+			"#4":    commits(feature),
+			"ABC-1": commits(feature, fix),
+		}),
+	})
+
+	expected := `# Release notes 1.2.0 (2020-03-04)
+
+## ABC
+
+- ABC-1
+  - XYZ-2 add the exporter (ABC-1, #4) (3333333)
+  - ABC-1 fix the parser (2222222)
+
+## XYZ
+
+- XYZ-2
+  - XYZ-2 add the exporter (ABC-1, #4) (3333333)
+
+## Other
+
+- #4
+  - XYZ-2 add the exporter (ABC-1, #4) (3333333)
+`
+
+	var out bytes.Buffer
+	if err := Markdown(&out, result, ""); err != nil {
+		t.Fatalf("Markdown returned an error: %v", err)
+	}
+
+	if out.String() != expected {
+		t.Errorf("wrong release notes.\ngot=%q\nwant=%q", out.String(), expected)
+	}
+
+	out.Reset()
+	if err := Markdown(&out, stringArray(), ""); err != nil {
+		t.Fatalf("Markdown returned an error: %v", err)
+	}
+
+	if out.String() != "# Release notes\n\nNo tickets.\n" {
+		t.Errorf("wrong release notes without tickets. got=%q", out.String())
+	}
+
+	out.Reset()
+	custom := `{{.To.Name}}:{{range .Projects}} {{.Key}}={{len .Tickets}}{{end}}`
+	if err := Markdown(&out, result, custom); err != nil {
+		t.Fatalf("Markdown returned an error: %v", err)
+	}
+
+	if out.String() != "1.2.0: ABC=1 XYZ=1 =1" {
+		t.Errorf("wrong custom release notes. got=%q", out.String())
+	}
+}
+
+func TestMarkdownErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		result   object.Object
+		expected string
+	}{
+		{"invalid template", "{{range .Tickets}", stringArray(), "invalid release notes template: "},
+		{"unknown field", "{{.Version}}", stringArray(), "unable to render the release notes: "},
+		{"invalid result", "", &object.Integer{Value: 3}, "unsupported result of the script"},
+	}
+
+	for _, tt := range tests {
+		var out bytes.Buffer
+		err := Markdown(&out, tt.result, tt.template)
+		if err == nil {
+			t.Errorf("%s: expected an error", tt.name)
+			continue
+		}
+
+		if !strings.HasPrefix(err.Error(), tt.expected) {
+			t.Errorf("%s: wrong error. got=%q, want prefix %q", tt.name, err.Error(), tt.expected)
+		}
+	}
+}
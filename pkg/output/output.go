@@ -0,0 +1,89 @@
+// Package output prints the result of a glif script, the value of its last statement (ex: the value returned by
+// 'diff(repo, from, to)').
+package output
+
+import (
+	"fmt"
+	"github.com/TurnsCoffeeIntoScripts/git-log-issue-finder/pkg/interpreter/object"
+	"io"
+	"strings"
+)
+
+// Text prints the result in a human readable format:
+//	- an array of tickets is printed on a single line: [ABC-1 XYZ-2]
+//	- the hash returned by 'diff' with the 'sides' option is printed as labeled lists, one per line:
+//	  New in <to>: [ABC-1] and Only in <from>: [XYZ-2]
+//	- the hash returned by 'diff' with the 'details' option is printed like its array of tickets
+//	- any other value is printed using its Inspect() representation
+// Nothing is printed when the script has no result (ex: the last statement is a 'let' or a call to 'print').
+func Text(w io.Writer, result object.Object) error {
+	if result == nil || result.Type() == object.NullObj {
+		return nil
+	}
+
+	var err error
+	switch result := result.(type) {
+	case *object.Array:
+		_, err = fmt.Fprintln(w, list(result))
+	case *object.Hash:
+		if added, removed, ok := sides(result); ok {
+			if added != nil {
+				_, err = fmt.Fprintf(w, "New in %s: %s\n", inspect(field(result, "to")), list(added))
+			}
+
+			if removed != nil && err == nil {
+				_, err = fmt.Fprintf(w, "Only in %s: %s\n", inspect(field(result, "from")), list(removed))
+			}
+
+			return err
+		}
+
+		if tickets, ok := field(result, "tickets").(*object.Array); ok {
+			_, err = fmt.Fprintln(w, list(tickets))
+			return err
+		}
+
+		_, err = fmt.Fprintln(w, result.Inspect())
+	default:
+		_, err = fmt.Fprintln(w, result.Inspect())
+	}
+
+	return err
+}
+
+// list formats the elements of an array like a slice of strings: [ABC-1 XYZ-2]
+func list(array *object.Array) string {
+	values := make([]string, 0, len(array.Elements))
+	for _, element := range array.Elements {
+		values = append(values, element.Inspect())
+	}
+
+	return "[" + strings.Join(values, " ") + "]"
+}
+
+// sides returns the 'added' and 'removed' arrays of the hash returned by 'diff' with the 'sides' option. At least
+// one of them is present in such a hash.
+func sides(hash *object.Hash) (*object.Array, *object.Array, bool) {
+	added, _ := field(hash, "added").(*object.Array)
+	removed, _ := field(hash, "removed").(*object.Array)
+
+	return added, removed, added != nil || removed != nil
+}
+
+// field returns the value of a key of the hash, nil if the key is absent
+func field(hash *object.Hash, key string) object.Object {
+	pair, ok := hash.Pairs[(&object.String{Value: key}).HashKey()]
+	if !ok {
+		return nil
+	}
+
+	return pair.Value
+}
+
+func inspect(value object.Object) string {
+	if value == nil {
+		return "?"
+	}
+
+	return value.Inspect()
+}
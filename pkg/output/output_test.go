@@ -0,0 +1,56 @@
+package output
+
+import (
+	"bytes"
+	"github.com/TurnsCoffeeIntoScripts/git-log-issue-finder/pkg/interpreter/object"
+	"testing"
+)
+
+func stringArray(values ...string) *object.Array {
+	elements := make([]object.Object, 0, len(values))
+	for _, value := range values {
+		elements = append(elements, &object.String{Value: value})
+	}
+
+	return &object.Array{Elements: elements}
+}
+
+func TestText(t *testing.T) {
+	from := &object.String{Value: "refs/tags/1.0.1"}
+	to := &object.String{Value: "HEAD"}
+
+	tests := []struct {
+		name     string
+		result   object.Object
+		expected string
+	}{
+		{"no result", nil, ""},
+		{"null", &object.Null{}, ""},
+		{"tickets", stringArray("ABC-1", "XYZ-2"), "[ABC-1 XYZ-2]\n"},
+		{"no tickets", stringArray(), "[]\n"},
+		{"both sides", object.NewStringHash(map[string]object.Object{
+			"from": from, "to": to, "added": stringArray("ABC-7"), "removed": stringArray("ABC-5", "ABC-6"),
+		}), "New in HEAD: [ABC-7]\nOnly in refs/tags/1.0.1: [ABC-5 ABC-6]\n"},
+		{"added side", object.NewStringHash(map[string]object.Object{
+			"from": from, "to": to, "added": stringArray("ABC-7"),
+		}), "New in HEAD: [ABC-7]\n"},
+		{"removed side", object.NewStringHash(map[string]object.Object{
+			"from": from, "to": to, "removed": stringArray(),
+		}), "Only in refs/tags/1.0.1: []\n"},
+		{"details", object.NewStringHash(map[string]object.Object{
+			"from": from, "to": to, "tickets": stringArray("ABC-1"),
+		}), "[ABC-1]\n"},
+		{"other value", &object.Integer{Value: 3}, "3\n"},
+	}
+
+	for _, tt := range tests {
+		var out bytes.Buffer
+		if err := Text(&out, tt.result); err != nil {
+			t.Fatalf("%s: Text returned an error: %v", tt.name, err)
+		}
+
+		if out.String() != tt.expected {
+			t.Errorf("%s: wrong output. got=%q, want=%q", tt.name, out.String(), tt.expected)
+		}
+	}
+}
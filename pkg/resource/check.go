@@ -0,0 +1,44 @@
+package resource
+
+// CheckRequest is the request of 'check'. The version is nil on the first check.
+type CheckRequest struct {
+	Source  Source   `json:"source"`
+	Version *Version `json:"version"`
+}
+
+// Run returns the versions since the current one (included), from the earliest to the latest. Only the latest
+// version is returned on the first check or when the current version no longer exists.
+func (request *CheckRequest) Run() ([]Version, error) {
+	repo, cleanup, err := request.Source.clone()
+	defer cleanup()
+	if err != nil {
+		return nil, err
+	}
+
+	tags, err := request.Source.tags(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	versions := make([]Version, 0, len(tags))
+	if len(tags) == 0 {
+		return versions, nil
+	}
+
+	// The tags are sorted from the latest to the earliest
+	current := 0
+	if request.Version != nil {
+		for i, tag := range tags {
+			if version(tag).Tag == request.Version.Tag {
+				current = i
+				break
+			}
+		}
+	}
+
+	for i := current; i >= 0; i-- {
+		versions = append(versions, version(tags[i]))
+	}
+
+	return versions, nil
+}
@@ -0,0 +1,126 @@
+package resource
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/TurnsCoffeeIntoScripts/git-log-issue-finder/pkg/interpreter/object"
+	"github.com/TurnsCoffeeIntoScripts/git-log-issue-finder/pkg/output"
+	"github.com/TurnsCoffeeIntoScripts/git-log-issue-finder/pkg/scl"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// InRequest is the request of 'in'
+type InRequest struct {
+	Source  Source  `json:"source"`
+	Version Version `json:"version"`
+}
+
+// Files written by 'in' in the destination directory
+const (
+	TagFile          = "tag"              // The tag of the version
+	RefFile          = "ref"              // The hash of the commit of the tag
+	FromFile         = "from"             // The previous matching tag, empty for the first version
+	TicketsFile      = "tickets"          // The tickets of the range, one per line
+	DocumentFile     = "tickets.json"     // The JSON document of the range (see output.Document)
+	ReleaseNotesFile = "release-notes.md" // The default release notes (see output.DefaultTemplate)
+)
+
+// inScript diffs the range ending at the requested version
+const inScript = `diff(repo, from, to, diffopts);`
+
+// Run writes the files of the range ending at the requested version in the destination directory. The range starts
+// at the previous matching tag, or at the first commit when there is none.
+func (request *InRequest) Run(dest string) (*Response, error) {
+	repo, cleanup, err := request.Source.clone()
+	defer cleanup()
+	if err != nil {
+		return nil, err
+	}
+
+	tags, err := request.Source.tags(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	var to, from *scl.GlifTag
+	for i, tag := range tags {
+		if version(tag).Tag == request.Version.Tag {
+			to = tag
+			if i+1 < len(tags) {
+				from = tags[i+1]
+			}
+			break
+		}
+	}
+
+	if to == nil {
+		return nil, fmt.Errorf("the version '%s' doesn't exist (no matching tag)", request.Version.Tag)
+	}
+
+	result, err := request.Source.eval(inScript, repo, map[string]object.Object{
+		"from": tagObject(from),
+		"to":   tagObject(to),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	document, err := output.NewDocument(result)
+	if err != nil {
+		return nil, err
+	}
+
+	// The repository was cloned in a temporary directory
+	document.Repository = request.Source.URI
+	documentJSON, err := json.MarshalIndent(document, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	var releaseNotes bytes.Buffer
+	if err := output.Markdown(&releaseNotes, result, ""); err != nil {
+		return nil, err
+	}
+
+	response := &Response{Version: version(to), Metadata: []Metadata{}}
+	fromName := ""
+	if from != nil {
+		fromName = version(from).Tag
+		response.Metadata = append(response.Metadata, Metadata{Name: "from", Value: fromName})
+	}
+
+	response.Metadata = append(response.Metadata, Metadata{Name: "tickets", Value: strings.Join(document.Tickets, ", ")})
+
+	tickets := strings.Join(document.Tickets, "\n")
+	if tickets != "" {
+		tickets += "\n"
+	}
+
+	files := []struct {
+		name    string
+		content string
+	}{
+		{TagFile, response.Version.Tag},
+		{RefFile, response.Version.Ref},
+		{FromFile, fromName},
+		{TicketsFile, tickets},
+		{DocumentFile, string(documentJSON) + "\n"},
+		{ReleaseNotesFile, releaseNotes.String()},
+	}
+
+	if err := os.MkdirAll(dest, 0755); err != nil {
+		return nil, fmt.Errorf("unable to create the destination directory: %v", err)
+	}
+
+	for _, file := range files {
+		if err := ioutil.WriteFile(filepath.Join(dest, file.name), []byte(file.content), 0644); err != nil {
+			return nil, fmt.Errorf("unable to write %s: %v", file.name, err)
+		}
+	}
+
+	return response, nil
+}
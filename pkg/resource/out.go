@@ -0,0 +1,125 @@
+package resource
+
+import (
+	"fmt"
+	"github.com/TurnsCoffeeIntoScripts/git-log-issue-finder/pkg/interpreter/object"
+	"github.com/TurnsCoffeeIntoScripts/git-log-issue-finder/pkg/output"
+	"github.com/TurnsCoffeeIntoScripts/git-log-issue-finder/pkg/scl"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+)
+
+// OutRequest is the request of 'out'
+type OutRequest struct {
+	Source Source    `json:"source"`
+	Params OutParams `json:"params"`
+}
+
+// OutParams are the parameters of the 'put' step
+type OutParams struct {
+	Repository string `json:"repository"` // The directory of the repository in the sources (ex: the output of a 'get')
+	Tag        string `json:"tag"`        // The name of the tag
+	TagFile    string `json:"tag_file"`   // The file containing the name of the tag in the sources (ex: 'version/number')
+	Message    string `json:"message"`    // The title of the message of the tag, 'Release <tag>' by default
+	Remote     string `json:"remote"`     // The remote the tag is pushed to, 'origin' by default
+}
+
+// outScript tags the HEAD of the repository with the tickets since the latest matching tag in the message
+const outScript = `
+let tag = createTag(repo, from, to, name, tagopts);
+diff(repo, from, tag, diffopts);
+`
+
+// Run creates an annotated tag on the HEAD of the repository and pushes it to the remote. The message of the tag
+// lists the tickets of the commits since the latest matching tag (see the 'createTag' builtin).
+func (request *OutRequest) Run(sources string) (*Response, error) {
+	name, err := request.Params.tagName(sources)
+	if err != nil {
+		return nil, err
+	}
+
+	if request.Params.Repository == "" {
+		return nil, fmt.Errorf("the repository parameter is required")
+	}
+
+	repo := &scl.GlifRepo{}
+	if err := repo.Open(filepath.Join(sources, request.Params.Repository)); err != nil {
+		return nil, err
+	}
+
+	head, err := repo.ResolveRevision("HEAD")
+	if err != nil {
+		return nil, err
+	}
+
+	tags, err := request.Source.tags(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	var from *scl.GlifTag
+	if len(tags) > 0 {
+		from = tags[0]
+	}
+
+	tagOptions := request.Source.diffOptions()
+	tagOptions["tagger"] = object.NewStringHash(map[string]object.Object{
+		"name":  &object.String{Value: request.Source.TaggerName},
+		"email": &object.String{Value: request.Source.TaggerEmail},
+	})
+
+	if request.Params.Message != "" {
+		tagOptions["message"] = &object.String{Value: request.Params.Message}
+	}
+
+	result, err := request.Source.eval(outScript, repo, map[string]object.Object{
+		"from":    tagObject(from),
+		"to":      &object.Revision{Value: &object.String{Value: "HEAD"}, Commit: head},
+		"name":    &object.String{Value: name},
+		"tagopts": object.NewStringHash(tagOptions),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	document, err := output.NewDocument(result)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := repo.PushTags(request.Params.Remote, request.Source.auth(), name); err != nil {
+		return nil, err
+	}
+
+	response := &Response{Version: Version{Tag: name, Ref: head.Hash.String()}, Metadata: []Metadata{}}
+	if from != nil {
+		response.Metadata = append(response.Metadata, Metadata{Name: "from", Value: version(from).Tag})
+	}
+
+	response.Metadata = append(response.Metadata, Metadata{Name: "tickets", Value: strings.Join(document.Tickets, ", ")})
+	return response, nil
+}
+
+// tagName returns the name of the tag, specified directly or read from the tag file
+func (params *OutParams) tagName(sources string) (string, error) {
+	if params.Tag != "" {
+		return params.Tag, nil
+	}
+
+	if params.TagFile == "" {
+		return "", fmt.Errorf("either the tag or the tag_file parameter is required")
+	}
+
+	content, err := ioutil.ReadFile(filepath.Join(sources, params.TagFile))
+	if err != nil {
+		return "", fmt.Errorf("unable to read the tag_file: %v", err)
+	}
+
+	name := strings.TrimSpace(string(content))
+	if name == "" {
+		return "", fmt.Errorf("the tag_file '%s' is empty", params.TagFile)
+	}
+
+	return name, nil
+}
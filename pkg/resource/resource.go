@@ -0,0 +1,231 @@
+// Package resource implements glif as a Concourse resource type (see https://concourse-ci.org/implementing-resource-types.html).
+//
+// The resource speaks the JSON protocol of Concourse: the request is read from the standard input and the response is
+// written to the standard output, the logs go to the standard error. Its versions are the tags matching a glif tag
+// format (see scl.TagFormatRegex):
+//	- check: the new matching tags since the current version
+//	- in: the tickets of the range ending at the requested version, written in files of the destination directory
+//	- out: tags (and annotates) the HEAD of a repository with the tickets since the latest matching tag
+package resource
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/TurnsCoffeeIntoScripts/git-log-issue-finder/pkg/interpreter/evaluator"
+	"github.com/TurnsCoffeeIntoScripts/git-log-issue-finder/pkg/interpreter/lexer"
+	"github.com/TurnsCoffeeIntoScripts/git-log-issue-finder/pkg/interpreter/object"
+	"github.com/TurnsCoffeeIntoScripts/git-log-issue-finder/pkg/interpreter/parser"
+	"github.com/TurnsCoffeeIntoScripts/git-log-issue-finder/pkg/scl"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+// Names of the entry points of the resource
+const (
+	Check = "check"
+	In    = "in"
+	Out   = "out"
+)
+
+// Default values of the source
+const (
+	defaultTagFilter = "$.$.$"
+	defaultTagOrder  = "semver"
+	defaultTickets   = "*"
+)
+
+// Source is the configuration of the resource in the pipeline
+type Source struct {
+	URI         string   `json:"uri"`          // URL (or path) of the git repository
+	Username    string   `json:"username"`     // Optional HTTP(S) credentials of the repository
+	Password    string   `json:"password"`     // (see Username)
+	TagFilter   string   `json:"tag_filter"`   // Tag format of the versions, '$.$.$' by default (see scl.TagFormatRegex)
+	TagOrder    string   `json:"tag_order"`    // 'semver' (default) or 'date' (see scl.ParseTagOrder)
+	Tickets     string   `json:"tickets"`      // The tickets regex (see the 'tickets' parameter), '*' by default
+	Trackers    []string `json:"trackers"`     // The issue trackers (see the 'trackers' option of 'diff'), Jira by default
+	TaggerName  string   `json:"tagger_name"`  // The identity of the tags created by 'out'
+	TaggerEmail string   `json:"tagger_email"` // (see TaggerName)
+}
+
+// Version is a version of the resource: a tag and the hash of its commit
+type Version struct {
+	Tag string `json:"tag"`
+	Ref string `json:"ref,omitempty"`
+}
+
+// Metadata is a field of the metadata of a version, displayed by Concourse
+type Metadata struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// Response is the response of 'in' and 'out'
+type Response struct {
+	Version  Version    `json:"version"`
+	Metadata []Metadata `json:"metadata"`
+}
+
+// Run runs an entry point of the resource. The arguments are the ones given by Concourse (the destination directory
+// of 'in' and the sources directory of 'out').
+func Run(command string, args []string, stdin io.Reader, stdout io.Writer) error {
+	var response interface{}
+	var err error
+
+	switch command {
+	case Check:
+		request := CheckRequest{}
+		if err = decode(stdin, &request); err == nil {
+			response, err = request.Run()
+		}
+	case In:
+		request := InRequest{}
+		if len(args) != 1 {
+			return fmt.Errorf("usage: in <destination directory>")
+		}
+
+		if err = decode(stdin, &request); err == nil {
+			response, err = request.Run(args[0])
+		}
+	case Out:
+		request := OutRequest{}
+		if len(args) != 1 {
+			return fmt.Errorf("usage: out <sources directory>")
+		}
+
+		if err = decode(stdin, &request); err == nil {
+			response, err = request.Run(args[0])
+		}
+	default:
+		return fmt.Errorf("unknown command '%s' (expected '%s', '%s' or '%s')", command, Check, In, Out)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return json.NewEncoder(stdout).Encode(response)
+}
+
+// decode reads the JSON request
+func decode(stdin io.Reader, request interface{}) error {
+	if err := json.NewDecoder(stdin).Decode(request); err != nil {
+		return fmt.Errorf("invalid request: %v", err)
+	}
+
+	return nil
+}
+
+// auth returns the authentication of the repository, nil when there are no credentials
+func (source *Source) auth() transport.AuthMethod {
+	if source.Username == "" && source.Password == "" {
+		return nil
+	}
+
+	return &http.BasicAuth{Username: source.Username, Password: source.Password}
+}
+
+// clone clones the repository in a temporary directory. The returned function removes the directory.
+func (source *Source) clone() (*scl.GlifRepo, func(), error) {
+	if source.URI == "" {
+		return nil, func() {}, fmt.Errorf("the uri of the source is required")
+	}
+
+	dir, err := ioutil.TempDir("", "glif-resource-")
+	if err != nil {
+		return nil, func() {}, fmt.Errorf("unable to create a temporary directory: %v", err)
+	}
+
+	cleanup := func() { _ = os.RemoveAll(dir) }
+	repo := &scl.GlifRepo{}
+	if err := repo.Clone(source.URI, dir, source.auth()); err != nil {
+		return nil, cleanup, err
+	}
+
+	return repo, cleanup, nil
+}
+
+// tags extracts the tags matching the tag filter, from the latest to the earliest
+func (source *Source) tags(repo *scl.GlifRepo) ([]*scl.GlifTag, error) {
+	format, orderName := source.TagFilter, source.TagOrder
+	if format == "" {
+		format = defaultTagFilter
+	}
+
+	if orderName == "" {
+		orderName = defaultTagOrder
+	}
+
+	order, err := scl.ParseTagOrder(orderName)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := repo.FetchAllMatchingTags(scl.TagFormatRegex(format), order); err != nil {
+		return nil, fmt.Errorf("invalid tag_filter '%s': %v", format, err)
+	}
+
+	return repo.MatchingTags(), nil
+}
+
+// version returns the version of a tag
+func version(tag *scl.GlifTag) Version {
+	return Version{Tag: plumbing.ReferenceName(tag.Name).Short(), Ref: tag.Commit.Hash.String()}
+}
+
+// tagObject converts a tag for the interpreter, NULL when there is no tag
+func tagObject(tag *scl.GlifTag) object.Object {
+	if tag == nil {
+		return evaluator.NULL
+	}
+
+	return &object.Tag{Value: &object.String{Value: tag.Name}, Tag: tag}
+}
+
+// diffOptions returns the options of 'diff' for the source: its trackers, and the commits of each ticket
+func (source *Source) diffOptions() map[string]object.Object {
+	trackers := make([]object.Object, 0, len(source.Trackers))
+	for _, tracker := range source.Trackers {
+		trackers = append(trackers, &object.String{Value: tracker})
+	}
+
+	return map[string]object.Object{
+		"trackers": &object.Array{Elements: trackers},
+		"commits":  evaluator.TRUE,
+	}
+}
+
+// eval runs the script with the repository, the tickets regex and the 'diffopts' variable of the source. The other
+// variables of the script are in vars.
+func (source *Source) eval(script string, repo *scl.GlifRepo, vars map[string]object.Object) (object.Object, error) {
+	tickets := source.Tickets
+	if tickets == "" {
+		tickets = defaultTickets
+	}
+
+	env := object.NewEnvironmentWithParams(tickets)
+	env.Set("repo", &object.Repo{Repo: *repo, Path: &object.String{Value: source.URI}})
+
+	env.Set("diffopts", object.NewStringHash(source.diffOptions()))
+
+	for name, value := range vars {
+		env.Set(name, value)
+	}
+
+	p := parser.NewWithOptions(lexer.New(script), false)
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		return nil, fmt.Errorf("error parsing script: %s", strings.Join(p.Errors(), ", "))
+	}
+
+	result := evaluator.Eval(program, env)
+	if errObj, ok := result.(*object.Error); ok {
+		return nil, fmt.Errorf("%s", errObj.Message)
+	}
+
+	return result, nil
+}
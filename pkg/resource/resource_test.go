@@ -0,0 +1,354 @@
+package resource
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/TurnsCoffeeIntoScripts/git-log-issue-finder/pkg/internal/testrepo"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// newTestRepo creates a repository with the tags 1.0.0 (ABC-1), 1.1.0 (ABC-2 and ABC-3) and 1.2.0 (ABC-4), and a
+// 'nightly' tag that doesn't match the default tag filter
+func newTestRepo(t *testing.T) *testrepo.Repository {
+	tr := testrepo.New(t)
+	tr.AnnotatedTag("1.0.0", tr.Commit("ABC-1 first"))
+	tr.Commit("ABC-2 second")
+	tr.AnnotatedTag("1.1.0", tr.Commit("fix: ABC-3 third"))
+	head := tr.Commit("ABC-4 fourth")
+	tr.AnnotatedTag("1.2.0", head)
+	tr.LightweightTag("nightly", head)
+
+	return tr
+}
+
+// revParse returns the hash of the revision
+func revParse(tr *testrepo.Repository, revision string) string {
+	return strings.TrimSpace(tr.Git("rev-parse", revision))
+}
+
+// run runs the command of the resource with the JSON request and decodes the response
+func run(t *testing.T, command string, args []string, request interface{}, response interface{}) {
+	stdin, err := json.Marshal(request)
+	if err != nil {
+		t.Fatalf("unable to encode the request: %v", err)
+	}
+
+	var stdout bytes.Buffer
+	if err := Run(command, args, bytes.NewReader(stdin), &stdout); err != nil {
+		t.Fatalf("%s returned an error: %v", command, err)
+	}
+
+	if err := json.Unmarshal(stdout.Bytes(), response); err != nil {
+		t.Fatalf("invalid response of %s: %v (%s)", command, err, stdout.String())
+	}
+}
+
+func tagNames(versions []Version) []string {
+	names := make([]string, 0, len(versions))
+	for _, v := range versions {
+		names = append(names, v.Tag)
+	}
+
+	return names
+}
+
+func TestCheck(t *testing.T) {
+	tr := newTestRepo(t)
+	defer tr.Cleanup()
+
+	tests := []struct {
+		name     string
+		source   Source
+		version  *Version
+		expected []string
+	}{
+		{"first check", Source{URI: tr.Dir}, nil, []string{"1.2.0"}},
+		{"current version", Source{URI: tr.Dir}, &Version{Tag: "1.0.0"}, []string{"1.0.0", "1.1.0", "1.2.0"}},
+		{"latest version", Source{URI: tr.Dir}, &Version{Tag: "1.2.0"}, []string{"1.2.0"}},
+		{"deleted version", Source{URI: tr.Dir}, &Version{Tag: "0.9.0"}, []string{"1.2.0"}},
+		{"tag filter", Source{URI: tr.Dir, TagFilter: "nightly"}, nil, []string{"nightly"}},
+		{"no matching tag", Source{URI: tr.Dir, TagFilter: "v$.$.$"}, nil, []string{}},
+	}
+
+	for _, tt := range tests {
+		var versions []Version
+		run(t, Check, nil, CheckRequest{Source: tt.source, Version: tt.version}, &versions)
+
+		if names := tagNames(versions); !reflect.DeepEqual(names, tt.expected) {
+			t.Errorf("%s: wrong versions. got=%v, want=%v", tt.name, names, tt.expected)
+		}
+	}
+
+	var versions []Version
+	run(t, Check, nil, CheckRequest{Source: Source{URI: tr.Dir}}, &versions)
+	if versions[0].Ref != revParse(tr, "1.2.0^{commit}") {
+		t.Errorf("wrong ref. got=%s, want=the commit of 1.2.0", versions[0].Ref)
+	}
+}
+
+func TestIn(t *testing.T) {
+	tr := newTestRepo(t)
+	defer tr.Cleanup()
+
+	dest, err := ioutil.TempDir("", "glif-resource-in-")
+	if err != nil {
+		t.Fatalf("unable to create temporary directory: %v", err)
+	}
+	defer func() { _ = os.RemoveAll(dest) }()
+
+	var response Response
+	run(t, In, []string{dest}, InRequest{Source: Source{URI: tr.Dir}, Version: Version{Tag: "1.1.0"}}, &response)
+
+	expectedVersion := Version{Tag: "1.1.0", Ref: revParse(tr, "1.1.0^{commit}")}
+	if response.Version != expectedVersion {
+		t.Errorf("wrong version. got=%+v, want=%+v", response.Version, expectedVersion)
+	}
+
+	expectedMetadata := []Metadata{{Name: "from", Value: "1.0.0"}, {Name: "tickets", Value: "ABC-3, ABC-2"}}
+	if !reflect.DeepEqual(response.Metadata, expectedMetadata) {
+		t.Errorf("wrong metadata. got=%+v, want=%+v", response.Metadata, expectedMetadata)
+	}
+
+	files := map[string]string{
+		TagFile:     "1.1.0",
+		RefFile:     expectedVersion.Ref,
+		FromFile:    "1.0.0",
+		TicketsFile: "ABC-3\nABC-2\n",
+	}
+
+	for name, expected := range files {
+		content, err := ioutil.ReadFile(filepath.Join(dest, name))
+		if err != nil {
+			t.Fatalf("unable to read %s: %v", name, err)
+		}
+
+		if string(content) != expected {
+			t.Errorf("wrong content of %s. got=%q, want=%q", name, content, expected)
+		}
+	}
+
+	content, err := ioutil.ReadFile(filepath.Join(dest, DocumentFile))
+	if err != nil {
+		t.Fatalf("unable to read %s: %v", DocumentFile, err)
+	}
+
+	document := struct {
+		Repository string   `json:"repository"`
+		Tickets    []string `json:"tickets"`
+	}{}
+	if err := json.Unmarshal(content, &document); err != nil {
+		t.Fatalf("invalid %s: %v", DocumentFile, err)
+	}
+
+	if document.Repository != tr.Dir || !reflect.DeepEqual(document.Tickets, []string{"ABC-3", "ABC-2"}) {
+		t.Errorf("wrong document. got=%+v", document)
+	}
+
+	notes, err := ioutil.ReadFile(filepath.Join(dest, ReleaseNotesFile))
+	if err != nil {
+		t.Fatalf("unable to read %s: %v", ReleaseNotesFile, err)
+	}
+
+	for _, expected := range []string{"# Release notes 1.1.0", "Changes since 1.0.0", "- ABC-3", "fix: ABC-3 third"} {
+		if !strings.Contains(string(notes), expected) {
+			t.Errorf("the release notes don't contain %q:\n%s", expected, notes)
+		}
+	}
+
+	// The first version starts at the first commit
+	run(t, In, []string{dest}, InRequest{Source: Source{URI: tr.Dir}, Version: Version{Tag: "1.0.0"}}, &response)
+	if from, _ := ioutil.ReadFile(filepath.Join(dest, FromFile)); len(from) != 0 {
+		t.Errorf("wrong from of the first version. got=%q", from)
+	}
+
+	expectedMetadata = []Metadata{{Name: "tickets", Value: "ABC-1"}}
+	if !reflect.DeepEqual(response.Metadata, expectedMetadata) {
+		t.Errorf("wrong metadata of the first version. got=%+v, want=%+v", response.Metadata, expectedMetadata)
+	}
+}
+
+func TestOut(t *testing.T) {
+	origin := newTestRepo(t)
+	defer origin.Cleanup()
+
+	sources, err := ioutil.TempDir("", "glif-resource-out-")
+	if err != nil {
+		t.Fatalf("unable to create temporary directory: %v", err)
+	}
+	defer func() { _ = os.RemoveAll(sources) }()
+
+	// The tag is pushed to a bare repository, like a 'get' of the git resource followed by a 'put' of glif
+	bareDir, repoDir := filepath.Join(sources, "origin.git"), filepath.Join(sources, "repo")
+	origin.Git("clone", "-q", "--bare", origin.Dir, bareDir)
+	origin.Git("clone", "-q", bareDir, repoDir)
+
+	bare, repo := testrepo.Open(t, bareDir), testrepo.Open(t, repoDir)
+	repo.Commit("ABC-5 fifth")
+	repo.Git("push", "-q", "origin", "HEAD")
+
+	if err := os.MkdirAll(filepath.Join(sources, "version"), 0755); err != nil {
+		t.Fatalf("unable to create the version directory: %v", err)
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(sources, "version", "number"), []byte("1.3.0\n"), 0644); err != nil {
+		t.Fatalf("unable to write the version: %v", err)
+	}
+
+	request := OutRequest{
+		Source: Source{URI: bare.Dir, TaggerName: "glif", TaggerEmail: "glif@example.com"},
+		Params: OutParams{Repository: "repo", TagFile: "version/number"},
+	}
+
+	var response Response
+	run(t, Out, []string{sources}, request, &response)
+
+	expectedVersion := Version{Tag: "1.3.0", Ref: revParse(repo, "HEAD")}
+	if response.Version != expectedVersion {
+		t.Errorf("wrong version. got=%+v, want=%+v", response.Version, expectedVersion)
+	}
+
+	expectedMetadata := []Metadata{{Name: "from", Value: "1.2.0"}, {Name: "tickets", Value: "ABC-5"}}
+	if !reflect.DeepEqual(response.Metadata, expectedMetadata) {
+		t.Errorf("wrong metadata. got=%+v, want=%+v", response.Metadata, expectedMetadata)
+	}
+
+	message := strings.TrimSpace(bare.Git("tag", "-l", "--format=%(contents)", "1.3.0"))
+	if message != "Release 1.3.0\n\nABC-5" {
+		t.Errorf("wrong message of the pushed tag. got=%q", message)
+	}
+
+	// The new tag is the next version of the resource
+	var versions []Version
+	run(t, Check, nil, CheckRequest{Source: request.Source, Version: &Version{Tag: "1.2.0"}}, &versions)
+	if names := tagNames(versions); !reflect.DeepEqual(names, []string{"1.2.0", "1.3.0"}) {
+		t.Errorf("wrong versions after out. got=%v", names)
+	}
+
+	request.Params = OutParams{Repository: "repo", Tag: "1.3.1", Message: "Hotfix 1.3.1"}
+	run(t, Out, []string{sources}, request, &response)
+	message = strings.TrimSpace(bare.Git("tag", "-l", "--format=%(contents)", "1.3.1"))
+	if message != "Hotfix 1.3.1" {
+		t.Errorf("wrong message of the pushed tag without tickets. got=%q", message)
+	}
+}
+
+func TestRunErrors(t *testing.T) {
+	tr := newTestRepo(t)
+	defer tr.Cleanup()
+
+	tests := []struct {
+		command  string
+		args     []string
+		request  string
+		expected string
+	}{
+		{"get", nil, `{}`, "unknown command 'get' (expected 'check', 'in' or 'out')"},
+		{In, nil, `{}`, "usage: in <destination directory>"},
+		{Out, []string{"a", "b"}, `{}`, "usage: out <sources directory>"},
+		{Check, nil, `{"source":`, "invalid request: unexpected EOF"},
+		{Check, nil, `{"source":{}}`, "the uri of the source is required"},
+		{Check, nil, `{"source":{"uri":"` + tr.Dir + `","tag_order":"name"}}`,
+			"unknown tag order 'name' (expected 'date' or 'semver')"},
+		{In, []string{tr.Dir}, `{"source":{"uri":"` + tr.Dir + `"},"version":{"tag":"0.9.0"}}`,
+			"the version '0.9.0' doesn't exist (no matching tag)"},
+		{Out, []string{tr.Dir}, `{"source":{"uri":"` + tr.Dir + `"},"params":{"repository":"."}}`,
+			"either the tag or the tag_file parameter is required"},
+		{Out, []string{tr.Dir}, `{"source":{"uri":"` + tr.Dir + `"},"params":{"tag":"1.3.0"}}`,
+			"the repository parameter is required"},
+	}
+
+	for _, tt := range tests {
+		var stdout bytes.Buffer
+		err := Run(tt.command, tt.args, strings.NewReader(tt.request), &stdout)
+		if err == nil || err.Error() != tt.expected {
+			t.Errorf("%s %s: wrong error. got=%v, want=%q", tt.command, tt.request, err, tt.expected)
+		}
+
+		if stdout.Len() != 0 {
+			t.Errorf("%s %s: unexpected response %q", tt.command, tt.request, stdout.String())
+		}
+	}
+}
+
+// TestCommand builds the glif-resource command and runs it like Concourse does: the request on the standard input,
+// the response on the standard output and the logs on the standard error
+func TestCommand(t *testing.T) {
+	tr := newTestRepo(t)
+	defer tr.Cleanup()
+
+	dir, err := ioutil.TempDir("", "glif-resource-command-")
+	if err != nil {
+		t.Fatalf("unable to create temporary directory: %v", err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	// Like /opt/resource, 'check' and 'in' are links to the command
+	command := filepath.Join(dir, "glif-resource")
+	build := exec.Command(filepath.Join(runtime.GOROOT(), "bin", "go"), "build", "-o", command, "../../cmd/glif-resource")
+	if out, err := build.CombinedOutput(); err != nil {
+		t.Fatalf("unable to build the command: %v\n%s", err, out)
+	}
+
+	for _, name := range []string{Check, In} {
+		if err := os.Symlink(command, filepath.Join(dir, name)); err != nil {
+			t.Fatalf("unable to link %s: %v", name, err)
+		}
+	}
+
+	dest := filepath.Join(dir, "dest")
+	source := `{"source":{"uri":"` + tr.Dir + `"}`
+
+	tests := []struct {
+		executable string
+		args       []string
+		request    string
+		exitCode   int
+		stdout     string
+		stderr     string
+	}{
+		{Check, nil, source + `}`, 0,
+			`[{"tag":"1.2.0","ref":"` + revParse(tr, "1.2.0^{commit}") + `"}]`, ""},
+		{"glif-resource", []string{In, dest}, source + `,"version":{"tag":"1.2.0"}}`, 0,
+			`{"version":{"tag":"1.2.0","ref":"` + revParse(tr, "1.2.0^{commit}") + `"},` +
+				`"metadata":[{"name":"from","value":"1.1.0"},{"name":"tickets","value":"ABC-4"}]}`, ""},
+		{"glif-resource", nil, "", 1, "", "usage: glif-resource check|in|out [directory]\n"},
+		{In, nil, source + `}`, 1, "", "usage: in <destination directory>\n"},
+		{Check, nil, `{"source":{}}`, 1, "", "the uri of the source is required\n"},
+	}
+
+	for _, tt := range tests {
+		var stdout, stderr bytes.Buffer
+		cmd := exec.Command(filepath.Join(dir, tt.executable), tt.args...)
+		cmd.Stdin, cmd.Stdout, cmd.Stderr = strings.NewReader(tt.request), &stdout, &stderr
+
+		exitCode := 0
+		if err := cmd.Run(); err != nil {
+			exitErr, ok := err.(*exec.ExitError)
+			if !ok {
+				t.Fatalf("unable to run %s: %v", tt.executable, err)
+			}
+
+			exitCode = exitErr.ExitCode()
+		}
+
+		if exitCode != tt.exitCode {
+			t.Errorf("%s %v: wrong exit code. got=%d, want=%d (%s)", tt.executable, tt.args, exitCode, tt.exitCode,
+				stderr.String())
+		}
+
+		if got := strings.TrimSpace(stdout.String()); got != tt.stdout {
+			t.Errorf("%s %v: wrong response. got=%s, want=%s", tt.executable, tt.args, got, tt.stdout)
+		}
+
+		if tt.exitCode != 0 && stderr.String() != tt.stderr {
+			t.Errorf("%s %v: wrong error. got=%q, want=%q", tt.executable, tt.args, stderr.String(), tt.stderr)
+		}
+	}
+}
@@ -0,0 +1,25 @@
+package scl
+
+import (
+	"strings"
+)
+
+// SplitMessage splits a commit message into its subject and its body the same way git does: the subject is the first
+// paragraph of the message (its lines joined by spaces) and the body is everything after the first blank line.
+func SplitMessage(message string) (subject string, body string) {
+	message = strings.Replace(message, "\r\n", "\n", -1)
+	message = strings.Trim(message, "\n")
+
+	paragraph := message
+	if idx := strings.Index(message, "\n\n"); idx >= 0 {
+		paragraph = message[:idx]
+		body = strings.TrimLeft(message[idx+2:], "\n")
+	}
+
+	lines := strings.Split(paragraph, "\n")
+	for i := range lines {
+		lines[i] = strings.TrimSpace(lines[i])
+	}
+
+	return strings.Join(lines, " "), body
+}
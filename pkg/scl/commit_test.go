@@ -0,0 +1,24 @@
+package scl
+
+import "testing"
+
+func TestSplitMessage(t *testing.T) {
+	tests := []struct {
+		message string
+		subject string
+		body    string
+	}{
+		{"ABC-1 subject", "ABC-1 subject", ""},
+		{"ABC-1 subject\n", "ABC-1 subject", ""},
+		{"ABC-1 subject\n\nbody line 1\nbody line 2\n", "ABC-1 subject", "body line 1\nbody line 2"},
+		{"ABC-1 subject\non two lines\n\nbody", "ABC-1 subject on two lines", "body"},
+		{"\n\nABC-1 subject\r\n\r\nbody\r\n", "ABC-1 subject", "body"},
+	}
+
+	for _, tt := range tests {
+		subject, body := SplitMessage(tt.message)
+		if subject != tt.subject || body != tt.body {
+			t.Errorf("SplitMessage(%q) is wrong. got=(%q, %q), want=(%q, %q)", tt.message, subject, body, tt.subject, tt.body)
+		}
+	}
+}
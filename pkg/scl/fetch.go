@@ -0,0 +1,173 @@
+package scl
+
+import (
+	"fmt"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"strings"
+)
+
+// FetchOptions specifies what is fetched by GlifRepo.Fetch
+type FetchOptions struct {
+	RemoteName string   // Name of the remote to fetch from. Defaults to 'origin'
+	RefSpecs   []string // Refspecs to fetch. Defaults to the refspecs configured for the remote
+	Tags       bool     // Fetch every tag of the remote instead of only those pointing into the fetched history
+	Prune      bool     // Remove the references that no longer exist on the remote (only those covered by the refspecs)
+}
+
+// Fetch does a 'Fetch' on the *git.Repository. A repository that is already up to date is not considered an error.
+// Used in the following builtin(s):
+//	- fetch
+func (glifRepo *GlifRepo) Fetch(opts FetchOptions) error {
+	if opts.RemoteName == "" {
+		opts.RemoteName = git.DefaultRemoteName
+	}
+
+	remote, err := glifRepo.GitRepo.Remote(opts.RemoteName)
+	if err != nil {
+		return fmt.Errorf("unable to find remote '%s': %v", opts.RemoteName, err)
+	}
+
+	refSpecs := make([]config.RefSpec, 0, len(opts.RefSpecs))
+	for _, spec := range opts.RefSpecs {
+		refSpec := config.RefSpec(spec)
+		if err := refSpec.Validate(); err != nil {
+			return fmt.Errorf("invalid refspec '%s': %v", spec, err)
+		}
+		refSpecs = append(refSpecs, refSpec)
+	}
+
+	fetchOptions := &git.FetchOptions{RemoteName: opts.RemoteName, RefSpecs: refSpecs}
+	if len(refSpecs) == 0 {
+		refSpecs = remote.Config().Fetch
+	}
+
+	updatedRefSpecs := refSpecs
+	if opts.Tags {
+		fetchOptions.Tags = git.AllTags
+		updatedRefSpecs = append([]config.RefSpec{tagsRefSpec}, refSpecs...)
+	}
+
+	remoteRefs, err := remoteReferences(remote)
+	if err != nil {
+		return fmt.Errorf("unable to list the references of remote '%s': %v", opts.RemoteName, err)
+	}
+
+	if err := glifRepo.unpackReferences(updatedRefSpecs, remoteRefs); err != nil {
+		return fmt.Errorf("unable to prepare references before fetching remote '%s': %v", opts.RemoteName, err)
+	}
+
+	err = glifRepo.GitRepo.Fetch(fetchOptions)
+	if err != nil && err != git.NoErrAlreadyUpToDate {
+		return fmt.Errorf("unable to fetch remote '%s': %v", opts.RemoteName, err)
+	}
+
+	if opts.Prune {
+		if err := glifRepo.prune(remoteRefs, refSpecs); err != nil {
+			return fmt.Errorf("unable to prune references of remote '%s': %v", opts.RemoteName, err)
+		}
+	}
+
+	return nil
+}
+
+// tagsRefSpec is the refspec used by go-git to fetch all the tags of a remote
+const tagsRefSpec = config.RefSpec("+refs/tags/*:refs/tags/*")
+
+// remoteReferences returns the hash of each reference of the remote, by name
+func remoteReferences(remote *git.Remote) (map[plumbing.ReferenceName]plumbing.Hash, error) {
+	refs, err := remote.List(&git.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	hashes := make(map[plumbing.ReferenceName]plumbing.Hash, len(refs))
+	for _, ref := range refs {
+		hashes[ref.Name()] = ref.Hash()
+	}
+
+	return hashes, nil
+}
+
+// unpackReferences writes a loose copy of the local references that the fetch is going to update: the destinations
+// of the refspecs whose hash differs on the remote. The go-git version in use fails to update a reference that only
+// exists in the 'packed-refs' file (which is the case right after a 'git clone') with the error 'reference has changed
+// concurrently': the expected value is compared with the empty loose file (see checkReferenceAndTruncate in
+// go-git/storage/filesystem/dotgit and
+// https://github.com/go-git/go-git/issues?q=%22reference+has+changed+concurrently%22). A loose reference takes
+// precedence over the packed one and has the same value, so git sees the exact same references. The references
+// that the fetch leaves untouched stay packed.
+func (glifRepo *GlifRepo) unpackReferences(refSpecs []config.RefSpec,
+	remoteRefs map[plumbing.ReferenceName]plumbing.Hash) error {
+	return glifRepo.forEachLocalReference(refSpecs, func(ref *plumbing.Reference, remote plumbing.ReferenceName) error {
+		hash, ok := remoteRefs[remote]
+		if !ok || hash == ref.Hash() {
+			return nil
+		}
+
+		return glifRepo.GitRepo.Storer.SetReference(ref)
+	})
+}
+
+// prune removes the local references that are the destination of one of the refspecs but whose source no longer
+// exists on the remote (same as 'git fetch --prune').
+func (glifRepo *GlifRepo) prune(remoteRefs map[plumbing.ReferenceName]plumbing.Hash, refSpecs []config.RefSpec) error {
+	stale := make([]plumbing.ReferenceName, 0)
+	err := glifRepo.forEachLocalReference(refSpecs, func(ref *plumbing.Reference, remote plumbing.ReferenceName) error {
+		if _, ok := remoteRefs[remote]; !ok {
+			stale = append(stale, ref.Name())
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, name := range stale {
+		if err := glifRepo.GitRepo.Storer.RemoveReference(name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// forEachLocalReference calls the function on each local (hash) reference that is a destination of the refspecs.
+// The name of the matching reference on the remote is also given to the function.
+func (glifRepo *GlifRepo) forEachLocalReference(refSpecs []config.RefSpec,
+	fn func(ref *plumbing.Reference, remoteName plumbing.ReferenceName) error) error {
+	// A reversed refspec matches the local references and gives back the name of the reference on the remote
+	reversed := make([]config.RefSpec, 0, len(refSpecs))
+	for _, spec := range refSpecs {
+		reversed = append(reversed, config.RefSpec(strings.TrimPrefix(spec.String(), "+")).Reverse())
+	}
+
+	iter, err := glifRepo.GitRepo.References()
+	if err != nil {
+		return err
+	}
+
+	matching := make(map[*plumbing.Reference]plumbing.ReferenceName)
+	_ = iter.ForEach(func(ref *plumbing.Reference) error {
+		if ref.Type() != plumbing.HashReference {
+			return nil
+		}
+
+		for _, spec := range reversed {
+			if spec.Match(ref.Name()) {
+				matching[ref] = spec.Dst(ref.Name())
+				break
+			}
+		}
+		return nil
+	})
+
+	for ref, remoteName := range matching {
+		if err := fn(ref, remoteName); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
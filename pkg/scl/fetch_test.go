@@ -0,0 +1,167 @@
+package scl
+
+import (
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+// fetchFixture is made of an upstream repository (where the commits are made), a bare repository cloned from it
+// (the remote) and a local clone of the bare repository (the repository on which glif runs).
+type fetchFixture struct {
+	upstream *testRepo
+	bare     *git.Repository
+	bareDir  string
+	local    *GlifRepo
+	localDir string
+}
+
+func newFetchFixture(t *testing.T) *fetchFixture {
+	f := &fetchFixture{upstream: newTestRepo(t)}
+	f.upstream.AnnotatedTag("1.0.0", f.upstream.Commit("ABC-1 first"))
+
+	var err error
+	f.bareDir, err = ioutil.TempDir("", "glif-bare-")
+	if err != nil {
+		t.Fatalf("unable to create temporary directory: %v", err)
+	}
+
+	f.bare, err = git.PlainClone(f.bareDir, true, &git.CloneOptions{URL: "file://" + f.upstream.Dir})
+	if err != nil {
+		t.Fatalf("unable to clone bare repository: %v", err)
+	}
+
+	f.localDir, err = ioutil.TempDir("", "glif-local-")
+	if err != nil {
+		t.Fatalf("unable to create temporary directory: %v", err)
+	}
+
+	if _, err = git.PlainClone(f.localDir, false, &git.CloneOptions{URL: "file://" + f.bareDir}); err != nil {
+		t.Fatalf("unable to clone local repository: %v", err)
+	}
+
+	// Like 'git clone' does, the references of the local repository are stored in the 'packed-refs' file
+	if out, err := exec.Command("git", "-C", f.localDir, "pack-refs", "--all").CombinedOutput(); err != nil {
+		t.Fatalf("unable to pack references: %v (%s)", err, out)
+	}
+
+	f.local = &GlifRepo{}
+	if err := f.local.Open(f.localDir); err != nil {
+		t.Fatalf("unable to open local repository: %v", err)
+	}
+
+	return f
+}
+
+func (f *fetchFixture) cleanup() {
+	f.upstream.Cleanup()
+	_ = os.RemoveAll(f.bareDir)
+	_ = os.RemoveAll(f.localDir)
+}
+
+// publish updates the bare repository with every branch and tag of the upstream repository
+func (f *fetchFixture) publish(t *testing.T) {
+	err := f.bare.Fetch(&git.FetchOptions{
+		RefSpecs: []config.RefSpec{"+refs/heads/*:refs/heads/*", "+refs/tags/*:refs/tags/*"},
+	})
+	if err != nil && err != git.NoErrAlreadyUpToDate {
+		t.Fatalf("unable to publish upstream commits: %v", err)
+	}
+}
+
+func (f *fetchFixture) localRef(name string) *plumbing.Reference {
+	ref, err := f.local.GitRepo.Reference(plumbing.ReferenceName(name), true)
+	if err != nil {
+		return nil
+	}
+
+	return ref
+}
+
+func TestFetch(t *testing.T) {
+	f := newFetchFixture(t)
+	defer f.cleanup()
+
+	if err := f.local.Fetch(FetchOptions{}); err != nil {
+		t.Errorf("fetching an up to date repository should not fail. got=%v", err)
+	}
+
+	second := f.upstream.Commit("ABC-2 second")
+	f.upstream.LightweightTag("1.1.0", second)
+	f.publish(t)
+
+	if err := f.local.Fetch(FetchOptions{RemoteName: "origin", Tags: true}); err != nil {
+		t.Fatalf("Fetch returned an error: %v", err)
+	}
+
+	if ref := f.localRef("refs/remotes/origin/master"); ref == nil || ref.Hash() != second {
+		t.Errorf("remote-tracking branch was not updated. got=%v, want=%s", ref, second)
+	}
+
+	if ref := f.localRef("refs/tags/1.1.0"); ref == nil || ref.Hash() != second {
+		t.Errorf("tag was not fetched. got=%v, want=%s", ref, second)
+	}
+
+	// The references that the fetch didn't change stay in the 'packed-refs' file
+	if _, err := os.Stat(filepath.Join(f.localDir, ".git", "refs", "tags", "1.0.0")); !os.IsNotExist(err) {
+		t.Errorf("the unchanged tag 1.0.0 was unpacked. got=%v", err)
+	}
+}
+
+func TestFetchRefSpecsAndPrune(t *testing.T) {
+	f := newFetchFixture(t)
+	defer f.cleanup()
+
+	head := f.upstream.Commit("ABC-2 second")
+	release := plumbing.NewBranchReferenceName("release")
+	if err := f.upstream.GitRepo.Storer.SetReference(plumbing.NewHashReference(release, head)); err != nil {
+		t.Fatalf("unable to create branch: %v", err)
+	}
+	f.publish(t)
+
+	opts := FetchOptions{RefSpecs: []string{"+refs/heads/release:refs/remotes/origin/release"}}
+	if err := f.local.Fetch(opts); err != nil {
+		t.Fatalf("Fetch returned an error: %v", err)
+	}
+
+	if ref := f.localRef("refs/remotes/origin/release"); ref == nil || ref.Hash() != head {
+		t.Fatalf("refspec was not fetched. got=%v, want=%s", ref, head)
+	}
+
+	if err := f.bare.Storer.RemoveReference(release); err != nil {
+		t.Fatalf("unable to remove branch: %v", err)
+	}
+
+	if err := f.local.Fetch(FetchOptions{Prune: true}); err != nil {
+		t.Fatalf("Fetch returned an error: %v", err)
+	}
+
+	if ref := f.localRef("refs/remotes/origin/release"); ref != nil {
+		t.Errorf("stale remote-tracking branch was not pruned")
+	}
+
+	if ref := f.localRef("refs/remotes/origin/master"); ref == nil {
+		t.Errorf("remote-tracking branch that still exists was pruned")
+	}
+}
+
+func TestFetchErrors(t *testing.T) {
+	f := newFetchFixture(t)
+	defer f.cleanup()
+
+	tests := []FetchOptions{
+		{RemoteName: "unknown"},
+		{RefSpecs: []string{"not a refspec"}},
+	}
+
+	for _, opts := range tests {
+		if err := f.local.Fetch(opts); err == nil {
+			t.Errorf("Fetch(%+v) should have failed", opts)
+		}
+	}
+}
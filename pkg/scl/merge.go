@@ -0,0 +1,48 @@
+package scl
+
+import (
+	"regexp"
+	"strings"
+)
+
+// mergeSubjectRegexes are the subjects of the merge commits created by git and by the hosting services, the group
+// contains the merged branch(es)
+var mergeSubjectRegexes = []*regexp.Regexp{
+	// git and GitLab: Merge branch 'feature/ABC-1' [of <url>] [into 'main']
+	// git: Merge branches 'feature/ABC-1' and 'feature/ABC-2' / Merge remote-tracking branch 'origin/feature/ABC-1'
+	regexp.MustCompile(`^Merge (?:remote-tracking )?branch(?:es)? ('[^']+'(?:(?:, | and )'[^']+')*)`),
+	// GitHub: Merge pull request #42 from org/feature/ABC-1 (the owner of the fork comes before the branch)
+	regexp.MustCompile(`^Merge pull request #[0-9]+ from [^/\s]+/(\S+)`),
+	// Bitbucket Server: Merge pull request #42 in PROJ/repo from feature/ABC-1 to main
+	regexp.MustCompile(`^Merge pull request #[0-9]+ in \S+ from (\S+) to \S+`),
+	// Bitbucket Cloud: Merged in feature/ABC-1 (pull request #42)
+	regexp.MustCompile(`^Merged in (\S+)(?: \(pull request #[0-9]+\))?`),
+}
+
+var quotedRegex = regexp.MustCompile(`'([^']+)'`)
+
+// MergedBranches returns the names of the branches merged by a commit, read from the standard merge messages of git,
+// GitHub, GitLab and Bitbucket. It returns nil if the subject of the message is not a merge message.
+func MergedBranches(message string) []string {
+	subject, _ := SplitMessage(message)
+
+	for _, regex := range mergeSubjectRegexes {
+		match := regex.FindStringSubmatch(subject)
+		if match == nil {
+			continue
+		}
+
+		if !strings.HasPrefix(match[1], "'") {
+			return []string{match[1]}
+		}
+
+		names := make([]string, 0)
+		for _, quoted := range quotedRegex.FindAllStringSubmatch(match[1], -1) {
+			names = append(names, quoted[1])
+		}
+
+		return names
+	}
+
+	return nil
+}
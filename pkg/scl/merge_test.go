@@ -0,0 +1,32 @@
+package scl
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestMergedBranches(t *testing.T) {
+	tests := []struct {
+		message  string
+		expected []string
+	}{
+		{"Merge branch 'feature/ABC-123-login'", []string{"feature/ABC-123-login"}},
+		{"Merge branch 'feature/ABC-123-login' into 'main'\n\nSee merge request group/repo!45", []string{"feature/ABC-123-login"}},
+		{"Merge branch 'ABC-1' of github.com:org/repo into main", []string{"ABC-1"}},
+		{"Merge branches 'ABC-1', 'ABC-2' and 'XYZ-3' into main", []string{"ABC-1", "ABC-2", "XYZ-3"}},
+		{"Merge remote-tracking branch 'origin/feature/ABC-4'", []string{"origin/feature/ABC-4"}},
+		{"Merge pull request #42 from org/ABC-123-fix\n\nFix the login", []string{"ABC-123-fix"}},
+		{"Merge pull request #42 from org/feature/ABC-123-fix", []string{"feature/ABC-123-fix"}},
+		{"Merge pull request #42 in PROJ/repo from feature/ABC-5 to master", []string{"feature/ABC-5"}},
+		{"Merged in feature/ABC-6-search (pull request #7)\n\nABC-6 search", []string{"feature/ABC-6-search"}},
+		{"Merge tag 'v1.2.0'", nil},
+		{"ABC-7 regular commit", nil},
+		{"Fix the merge of 'feature/ABC-8'", nil},
+	}
+
+	for _, tt := range tests {
+		if got := MergedBranches(tt.message); fmt.Sprint(got) != fmt.Sprint(tt.expected) || (got == nil) != (tt.expected == nil) {
+			t.Errorf("MergedBranches(%q) is wrong. got=%q, want=%q", tt.message, got, tt.expected)
+		}
+	}
+}
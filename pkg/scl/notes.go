@@ -0,0 +1,292 @@
+package scl
+
+import (
+	"fmt"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"sort"
+	"strings"
+)
+
+// DefaultNotesRef is the reference of the notes used by 'git notes' when none is specified
+const DefaultNotesRef = "refs/notes/commits"
+
+// Notes gives access to the notes (see 'git notes') of a notes reference. The notes are stored in the tree of the
+// commit of the reference: each blob is the note of the commit having its path as hash. Git splits the paths in
+// subdirectories when there are many notes (fanout, ex: 'ab/cdef...'), both layouts are supported.
+type Notes struct {
+	Ref    plumbing.ReferenceName
+	commit plumbing.Hash // zero when the reference does not exist
+	tree   *object.Tree  // nil when the reference does not exist
+}
+
+// NotesRefName expands a short notes reference the same way git does (ex: 'jira' is 'refs/notes/jira')
+func NotesRefName(name string) plumbing.ReferenceName {
+	if name == "" {
+		return DefaultNotesRef
+	}
+
+	if !strings.HasPrefix(name, "refs/") {
+		if strings.HasPrefix(name, "notes/") {
+			name = "refs/" + name
+		} else {
+			name = "refs/notes/" + name
+		}
+	}
+
+	return plumbing.ReferenceName(name)
+}
+
+// Notes opens the notes of the specified reference (see NotesRefName). A reference that does not exist simply
+// contains no note.
+func (glifRepo *GlifRepo) Notes(ref string) (*Notes, error) {
+	notes := &Notes{Ref: NotesRefName(ref)}
+
+	reference, err := glifRepo.GitRepo.Reference(notes.Ref, true)
+	if err == plumbing.ErrReferenceNotFound {
+		return notes, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("unable to resolve the notes reference '%s': %v", notes.Ref, err)
+	}
+
+	commit, err := glifRepo.GitRepo.CommitObject(reference.Hash())
+	if err != nil {
+		return nil, fmt.Errorf("unable to load the notes commit of '%s': %v", notes.Ref, err)
+	}
+	notes.commit = commit.Hash
+
+	if notes.tree, err = commit.Tree(); err != nil {
+		return nil, fmt.Errorf("unable to load the notes tree of '%s': %v", notes.Ref, err)
+	}
+
+	return notes, nil
+}
+
+// Note returns the note attached to the commit. The second value is false if the commit has no note.
+func (notes *Notes) Note(hash plumbing.Hash) (string, bool, error) {
+	if notes.tree == nil {
+		return "", false, nil
+	}
+
+	tree := notes.tree
+	remaining := hash.String()
+
+	for {
+		var subtree *object.TreeEntry
+
+		for i := range tree.Entries {
+			entry := &tree.Entries[i]
+			if entry.Name == remaining && entry.Mode.IsFile() {
+				return notes.read(tree, entry, hash)
+			}
+
+			// Fanout: the first characters of the hash are a directory
+			if entry.Mode == filemode.Dir && len(entry.Name) == 2 && strings.HasPrefix(remaining, entry.Name) {
+				subtree = entry
+			}
+		}
+
+		if subtree == nil {
+			return "", false, nil
+		}
+
+		var err error
+		if tree, err = tree.Tree(subtree.Name); err != nil {
+			return "", false, fmt.Errorf("unable to read the notes of '%s': %v", notes.Ref, err)
+		}
+
+		remaining = remaining[len(subtree.Name):]
+	}
+}
+
+func (notes *Notes) read(tree *object.Tree, entry *object.TreeEntry, hash plumbing.Hash) (string, bool, error) {
+	file, err := tree.TreeEntryFile(entry)
+	if err != nil {
+		return "", false, fmt.Errorf("unable to load the note of commit %s: %v", hash, err)
+	}
+
+	content, err := file.Contents()
+	if err != nil {
+		return "", false, fmt.Errorf("unable to read the note of commit %s: %v", hash, err)
+	}
+
+	return content, true, nil
+}
+
+// notesMessage is the message of the commits created by AddNote (like 'git notes add' does)
+const notesMessage = "Notes added by 'git-log-issue-finder'\n"
+
+// AddNote attaches the note to the commit in the notes reference (see NotesRefName), like 'git notes add' does. An
+// existing note is only replaced when 'force' is true, adding the same note again does nothing. The signature is the
+// author and committer of the commit created on the notes reference.
+func (glifRepo *GlifRepo) AddNote(ref string, hash plumbing.Hash, note string, signature object.Signature, force bool) error {
+	notes, err := glifRepo.Notes(ref)
+	if err != nil {
+		return err
+	}
+
+	if !strings.HasSuffix(note, "\n") {
+		note += "\n"
+	}
+
+	existing, found, err := notes.Note(hash)
+	if err != nil {
+		return err
+	}
+
+	if found && existing == note {
+		return nil
+	} else if found && !force {
+		return fmt.Errorf("commit %s already has a note in '%s'", hash, notes.Ref)
+	}
+
+	entries := make(map[string]plumbing.Hash)
+	others := make([]object.TreeEntry, 0)
+	if notes.tree != nil {
+		if err := collectNotes(notes.tree, "", entries, &others); err != nil {
+			return fmt.Errorf("unable to read the notes of '%s': %v", notes.Ref, err)
+		}
+	}
+
+	if entries[hash.String()], err = glifRepo.storeBlob(note); err != nil {
+		return fmt.Errorf("unable to store the note: %v", err)
+	}
+
+	tree, err := glifRepo.storeNotesTree(entries, others, notesFanout(len(entries)))
+	if err != nil {
+		return fmt.Errorf("unable to store the notes tree: %v", err)
+	}
+
+	commit := &object.Commit{Author: signature, Committer: signature, Message: notesMessage, TreeHash: tree}
+	if !notes.commit.IsZero() {
+		commit.ParentHashes = []plumbing.Hash{notes.commit}
+	}
+
+	commitHash, err := glifRepo.storeObject(commit)
+	if err != nil {
+		return fmt.Errorf("unable to store the notes commit: %v", err)
+	}
+
+	if err := glifRepo.GitRepo.Storer.SetReference(plumbing.NewHashReference(notes.Ref, commitHash)); err != nil {
+		return fmt.Errorf("unable to update the notes reference '%s': %v", notes.Ref, err)
+	}
+
+	return nil
+}
+
+// collectNotes gathers the blob of every note of the tree, whatever its fanout. The other entries of the root of the
+// tree (not a note) are kept as is.
+func collectNotes(tree *object.Tree, prefix string, entries map[string]plumbing.Hash, others *[]object.TreeEntry) error {
+	for _, entry := range tree.Entries {
+		name := prefix + entry.Name
+
+		switch {
+		case entry.Mode.IsFile() && isHex(name) && len(name) == 2*len(plumbing.ZeroHash):
+			entries[name] = entry.Hash
+		case entry.Mode == filemode.Dir && len(entry.Name) == 2 && isHex(entry.Name):
+			subtree, err := tree.Tree(entry.Name)
+			if err != nil {
+				return err
+			}
+
+			if err := collectNotes(subtree, name, entries, others); err != nil {
+				return err
+			}
+		case prefix == "":
+			*others = append(*others, entry)
+		}
+	}
+
+	return nil
+}
+
+func isHex(s string) bool {
+	for _, r := range s {
+		if !strings.ContainsRune("0123456789abcdef", r) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// notesFanout returns the number of directory levels used to store the notes, git splits the paths once a tree would
+// contain more than 256 entries
+func notesFanout(count int) int {
+	fanout := 0
+	for limit := 256; count > limit && fanout < 2; limit *= 256 {
+		fanout++
+	}
+
+	return fanout
+}
+
+func (glifRepo *GlifRepo) storeNotesTree(entries map[string]plumbing.Hash, others []object.TreeEntry, fanout int) (plumbing.Hash, error) {
+	tree := &object.Tree{Entries: append([]object.TreeEntry{}, others...)}
+
+	if fanout == 0 {
+		for name, blob := range entries {
+			tree.Entries = append(tree.Entries, object.TreeEntry{Name: name, Mode: filemode.Regular, Hash: blob})
+		}
+	} else {
+		subtrees := make(map[string]map[string]plumbing.Hash)
+		for name, blob := range entries {
+			if subtrees[name[:2]] == nil {
+				subtrees[name[:2]] = make(map[string]plumbing.Hash)
+			}
+			subtrees[name[:2]][name[2:]] = blob
+		}
+
+		for dir, subEntries := range subtrees {
+			subtree, err := glifRepo.storeNotesTree(subEntries, nil, fanout-1)
+			if err != nil {
+				return plumbing.ZeroHash, err
+			}
+
+			tree.Entries = append(tree.Entries, object.TreeEntry{Name: dir, Mode: filemode.Dir, Hash: subtree})
+		}
+	}
+
+	// Git sorts the entries by name, a directory being compared as if its name ended with '/'
+	sortName := func(entry object.TreeEntry) string {
+		if entry.Mode == filemode.Dir {
+			return entry.Name + "/"
+		}
+		return entry.Name
+	}
+	sort.Slice(tree.Entries, func(i, j int) bool { return sortName(tree.Entries[i]) < sortName(tree.Entries[j]) })
+
+	return glifRepo.storeObject(tree)
+}
+
+func (glifRepo *GlifRepo) storeObject(encoder interface {
+	Encode(plumbing.EncodedObject) error
+}) (plumbing.Hash, error) {
+	obj := glifRepo.GitRepo.Storer.NewEncodedObject()
+	if err := encoder.Encode(obj); err != nil {
+		return plumbing.ZeroHash, err
+	}
+
+	return glifRepo.GitRepo.Storer.SetEncodedObject(obj)
+}
+
+func (glifRepo *GlifRepo) storeBlob(content string) (plumbing.Hash, error) {
+	obj := glifRepo.GitRepo.Storer.NewEncodedObject()
+	obj.SetType(plumbing.BlobObject)
+
+	w, err := obj.Writer()
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+
+	if _, err := w.Write([]byte(content)); err != nil {
+		return plumbing.ZeroHash, err
+	}
+
+	if err := w.Close(); err != nil {
+		return plumbing.ZeroHash, err
+	}
+
+	return glifRepo.GitRepo.Storer.SetEncodedObject(obj)
+}
@@ -0,0 +1,247 @@
+package scl
+
+import (
+	"fmt"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"sort"
+	"testing"
+)
+
+// blob stores the content as a blob
+func (g *graphBuilder) blob(content string) plumbing.Hash {
+	obj := g.repo.Storer.NewEncodedObject()
+	obj.SetType(plumbing.BlobObject)
+
+	w, err := obj.Writer()
+	if err != nil {
+		g.tb.Fatalf("unable to write blob: %v", err)
+	}
+	_, _ = w.Write([]byte(content))
+	_ = w.Close()
+
+	hash, err := g.repo.Storer.SetEncodedObject(obj)
+	if err != nil {
+		g.tb.Fatalf("unable to store blob: %v", err)
+	}
+
+	return hash
+}
+
+// notes creates a notes commit on the reference, the paths of the notes are split in directories of two characters
+// 'fanout' times
+func (g *graphBuilder) notes(ref string, notes map[plumbing.Hash]string, fanout int) {
+	type node struct {
+		children map[string]*node
+		blob     plumbing.Hash
+	}
+
+	root := &node{children: make(map[string]*node)}
+	for hash, note := range notes {
+		current, path := root, hash.String()
+		for i := 0; i < fanout; i++ {
+			child, ok := current.children[path[:2]]
+			if !ok {
+				child = &node{children: make(map[string]*node)}
+				current.children[path[:2]] = child
+			}
+			current, path = child, path[2:]
+		}
+
+		current.children[path] = &node{blob: g.blob(note)}
+	}
+
+	var storeTree func(n *node) plumbing.Hash
+	storeTree = func(n *node) plumbing.Hash {
+		tree := &object.Tree{}
+		for name, child := range n.children {
+			if child.children == nil {
+				tree.Entries = append(tree.Entries, object.TreeEntry{Name: name, Mode: filemode.Regular, Hash: child.blob})
+			} else {
+				tree.Entries = append(tree.Entries, object.TreeEntry{Name: name, Mode: filemode.Dir, Hash: storeTree(child)})
+			}
+		}
+		sort.Slice(tree.Entries, func(i, j int) bool { return tree.Entries[i].Name < tree.Entries[j].Name })
+
+		return g.store(tree)
+	}
+
+	signature := object.Signature{Name: "glif", Email: "glif@example.com", When: g.clock}
+	commit := g.store(&object.Commit{Author: signature, Committer: signature, Message: "Notes added", TreeHash: storeTree(root)})
+
+	if err := g.repo.Storer.SetReference(plumbing.NewHashReference(plumbing.ReferenceName(ref), commit)); err != nil {
+		g.tb.Fatalf("unable to set notes reference: %v", err)
+	}
+}
+
+func TestNotes(t *testing.T) {
+	g := newGraphBuilder(t)
+
+	first := g.commit("first")
+	second := g.commit("second", first)
+	third := g.commit("third", second)
+
+	g.notes(DefaultNotesRef, map[plumbing.Hash]string{first: "ABC-1\n", second: "ABC-2\n"}, 0)
+	g.notes("refs/notes/jira", map[plumbing.Hash]string{second: "XYZ-2\n", third: "XYZ-3\n"}, 2)
+
+	tests := []struct {
+		ref      string
+		hash     plumbing.Hash
+		expected string
+		found    bool
+	}{
+		{"", first, "ABC-1\n", true},
+		{"", second, "ABC-2\n", true},
+		{"", third, "", false},
+		{"jira", first, "", false},
+		{"jira", second, "XYZ-2\n", true},
+		{"refs/notes/jira", third, "XYZ-3\n", true},
+		{"notes/missing", first, "", false},
+	}
+
+	for _, tt := range tests {
+		notes, err := g.glifRepo().Notes(tt.ref)
+		if err != nil {
+			t.Fatalf("Notes(%s) returned an error: %v", tt.ref, err)
+		}
+
+		note, found, err := notes.Note(tt.hash)
+		if err != nil {
+			t.Fatalf("Note returned an error: %v", err)
+		}
+
+		if note != tt.expected || found != tt.found {
+			t.Errorf("wrong note of %s in '%s'. got=(%q, %t), want=(%q, %t)", tt.hash, tt.ref, note, found,
+				tt.expected, tt.found)
+		}
+	}
+}
+
+func TestNotesRefName(t *testing.T) {
+	tests := map[string]plumbing.ReferenceName{
+		"":                   "refs/notes/commits",
+		"jira":               "refs/notes/jira",
+		"notes/jira":         "refs/notes/jira",
+		"refs/notes/commits": "refs/notes/commits",
+	}
+
+	for name, expected := range tests {
+		if got := NotesRefName(name); got != expected {
+			t.Errorf("NotesRefName(%q) is wrong. got=%s, want=%s", name, got, expected)
+		}
+	}
+}
+
+func TestAddNote(t *testing.T) {
+	tr := newTestRepo(t)
+	defer tr.Cleanup()
+
+	first := tr.Commit("ABC-1 first")
+	second := tr.Commit("ABC-2 second")
+	// A note created by git must be kept
+	tr.Git("notes", "add", "-m", "existing note", first.String())
+
+	glifRepo := tr.glifRepo()
+	signature := *tr.Signature()
+
+	if err := glifRepo.AddNote("", second, "ABC-2\nABC-3", signature, false); err != nil {
+		t.Fatalf("AddNote returned an error: %v", err)
+	}
+
+	if got := tr.Git("notes", "show", second.String()); got != "ABC-2\nABC-3\n" {
+		t.Errorf("wrong note read by git. got=%q", got)
+	}
+
+	if got := tr.Git("notes", "show", first.String()); got != "existing note\n" {
+		t.Errorf("the existing note was not kept. got=%q", got)
+	}
+
+	// Adding the same note again does nothing, a different note requires 'force'
+	if err := glifRepo.AddNote("", second, "ABC-2\nABC-3\n", signature, false); err != nil {
+		t.Errorf("AddNote of the same note returned an error: %v", err)
+	}
+
+	err := glifRepo.AddNote("", second, "ABC-4", signature, false)
+	if expected := "commit " + second.String() + " already has a note in 'refs/notes/commits'"; err == nil || err.Error() != expected {
+		t.Errorf("AddNote should fail when a note exists. got=%v, want=%s", err, expected)
+	}
+
+	if err := glifRepo.AddNote("", second, "ABC-4", signature, true); err != nil {
+		t.Fatalf("AddNote with force returned an error: %v", err)
+	}
+
+	if got := tr.Git("notes", "show", second.String()); got != "ABC-4\n" {
+		t.Errorf("wrong note after force. got=%q", got)
+	}
+
+	// One commit per note on the notes reference, authored by the signature
+	if got := tr.Git("log", "--format=%an <%ae> %s", "refs/notes/commits"); got !=
+		"glif <glif@example.com> Notes added by 'git-log-issue-finder'\n"+
+			"glif <glif@example.com> Notes added by 'git-log-issue-finder'\n"+
+			"glif <glif@example.com> Notes added by 'git notes add'\n" {
+		t.Errorf("wrong history of the notes reference. got=%q", got)
+	}
+
+	if err := glifRepo.AddNote("jira", first, "XYZ-1", signature, false); err != nil {
+		t.Fatalf("AddNote on another reference returned an error: %v", err)
+	}
+
+	if got := tr.Git("notes", "--ref", "jira", "show", first.String()); got != "XYZ-1\n" {
+		t.Errorf("wrong note in refs/notes/jira. got=%q", got)
+	}
+}
+
+func TestAddNoteFanout(t *testing.T) {
+	g := newGraphBuilder(t)
+
+	commits := make([]plumbing.Hash, 0)
+	notes := make(map[plumbing.Hash]string)
+	parent := plumbing.ZeroHash
+	for i := 0; i < 300; i++ {
+		parent = g.chain(fmt.Sprintf("commit %d", i), 1, parent)
+		commits = append(commits, parent)
+		notes[parent] = fmt.Sprintf("note %d\n", i)
+	}
+
+	// The existing notes use a fanout, the new tree must contain every note with a fanout as well
+	g.notes(DefaultNotesRef, notes, 1)
+	last := g.commit("last", parent)
+
+	glifRepo := g.glifRepo()
+	if err := glifRepo.AddNote("", last, "last note", object.Signature{Name: "glif", Email: "glif@example.com"}, false); err != nil {
+		t.Fatalf("AddNote returned an error: %v", err)
+	}
+
+	readNotes, err := glifRepo.Notes("")
+	if err != nil {
+		t.Fatalf("Notes returned an error: %v", err)
+	}
+
+	for _, hash := range append(commits, last) {
+		note, found, err := readNotes.Note(hash)
+		if err != nil || !found {
+			t.Fatalf("note of %s not found: %v", hash, err)
+		}
+
+		if expected, ok := notes[hash]; ok && note != expected {
+			t.Errorf("wrong note of %s. got=%q, want=%q", hash, note, expected)
+		}
+	}
+
+	for _, entry := range readNotes.tree.Entries {
+		if len(entry.Name) != 2 || entry.Mode != filemode.Dir {
+			t.Errorf("the notes should be split in directories. got=%s", entry.Name)
+		}
+	}
+}
+
+func TestNotesFanout(t *testing.T) {
+	tests := map[int]int{0: 0, 256: 0, 257: 1, 65536: 1, 65537: 2}
+
+	for count, expected := range tests {
+		if got := notesFanout(count); got != expected {
+			t.Errorf("notesFanout(%d) is wrong. got=%d, want=%d", count, got, expected)
+		}
+	}
+}
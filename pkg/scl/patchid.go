@@ -0,0 +1,193 @@
+package scl
+
+import (
+	"crypto/sha1"
+	"fmt"
+	"github.com/go-git/go-git/v5/plumbing"
+	fdiff "github.com/go-git/go-git/v5/plumbing/format/diff"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"io"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// PatchID computes a hash of the normalized changes of a commit compared to its parent, similar to 'git patch-id'.
+// Two commits with the same changes (ex: a commit and its cherry-pick on another branch) have the same patch-id even
+// though their hashes differ. The normalization ignores:
+//	- the whitespaces
+//	- the line numbers and the unchanged lines surrounding the changes (the context)
+//	- the commit message, author and dates
+// Merge commits and commits without changes do not have a patch-id, the second value is false for them.
+func PatchID(c *object.Commit) (plumbing.Hash, bool, error) {
+	if c.NumParents() > 1 {
+		return plumbing.ZeroHash, false, nil
+	}
+
+	tree, err := c.Tree()
+	if err != nil {
+		return plumbing.ZeroHash, false, err
+	}
+
+	parentTree := &object.Tree{}
+	if c.NumParents() == 1 {
+		parent, err := c.Parent(0)
+		if err != nil {
+			return plumbing.ZeroHash, false, err
+		}
+
+		if parentTree, err = parent.Tree(); err != nil {
+			return plumbing.ZeroHash, false, err
+		}
+	}
+
+	patch, err := parentTree.Patch(tree)
+	if err != nil {
+		return plumbing.ZeroHash, false, err
+	}
+
+	filePatches := patch.FilePatches()
+	if len(filePatches) == 0 {
+		return plumbing.ZeroHash, false, nil
+	}
+
+	sort.SliceStable(filePatches, func(i, j int) bool {
+		return filePatchPath(filePatches[i]) < filePatchPath(filePatches[j])
+	})
+
+	hasher := sha1.New()
+	for _, filePatch := range filePatches {
+		writeFilePatch(hasher, filePatch)
+	}
+
+	var id plumbing.Hash
+	copy(id[:], hasher.Sum(nil))
+
+	return id, true, nil
+}
+
+func filePatchPath(filePatch fdiff.FilePatch) string {
+	from, to := filePatch.Files()
+	if to != nil {
+		return to.Path()
+	}
+
+	return from.Path()
+}
+
+func writeFilePatch(w io.Writer, filePatch fdiff.FilePatch) {
+	from, to := filePatch.Files()
+
+	fromPath, toPath := "/dev/null", "/dev/null"
+	if from != nil {
+		fromPath = from.Path()
+	}
+	if to != nil {
+		toPath = to.Path()
+	}
+	_, _ = fmt.Fprintf(w, "diff %s %s\n", fromPath, toPath)
+
+	// The content of a binary file is not available, its blobs identify the change
+	if filePatch.IsBinary() {
+		fromHash, toHash := plumbing.ZeroHash, plumbing.ZeroHash
+		if from != nil {
+			fromHash = from.Hash()
+		}
+		if to != nil {
+			toHash = to.Hash()
+		}
+		_, _ = fmt.Fprintf(w, "binary %s %s\n", fromHash, toHash)
+		return
+	}
+
+	for _, chunk := range filePatch.Chunks() {
+		prefix := ""
+		switch chunk.Type() {
+		case fdiff.Add:
+			prefix = "+"
+		case fdiff.Delete:
+			prefix = "-"
+		default:
+			continue
+		}
+
+		for _, line := range strings.Split(strings.TrimSuffix(chunk.Content(), "\n"), "\n") {
+			_, _ = io.WriteString(w, prefix+removeSpaces(line)+"\n")
+		}
+	}
+}
+
+func removeSpaces(line string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return -1
+		}
+		return r
+	}, line)
+}
+
+// RemoveCherryPicks removes the commits that have an equivalent commit (same patch-id, see PatchID) on the other side
+// of the range, like 'git log --cherry-pick' does. The first value is the range without these commits, the second
+// contains the commits of the 'to' side that were removed: they were already applied on the 'from' side (backported).
+func RemoveCherryPicks(commitRange *CommitRange) (*CommitRange, []*object.Commit, error) {
+	if len(commitRange.FromOnly) == 0 || len(commitRange.ToOnly) == 0 {
+		return commitRange, make([]*object.Commit, 0), nil
+	}
+
+	fromIDs, err := patchIDs(commitRange.FromOnly)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	toIDs, err := patchIDs(commitRange.ToOnly)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	fromSet := make(map[plumbing.Hash]bool)
+	for _, id := range fromIDs {
+		fromSet[id] = true
+	}
+
+	toSet := make(map[plumbing.Hash]bool)
+	for _, id := range toIDs {
+		toSet[id] = true
+	}
+
+	result := &CommitRange{ToOnly: make([]*object.Commit, 0), FromOnly: make([]*object.Commit, 0)}
+	backported := make([]*object.Commit, 0)
+
+	for _, c := range commitRange.ToOnly {
+		if id, ok := toIDs[c]; ok && fromSet[id] {
+			backported = append(backported, c)
+		} else {
+			result.ToOnly = append(result.ToOnly, c)
+		}
+	}
+
+	for _, c := range commitRange.FromOnly {
+		if id, ok := fromIDs[c]; !ok || !toSet[id] {
+			result.FromOnly = append(result.FromOnly, c)
+		}
+	}
+
+	return result, backported, nil
+}
+
+// patchIDs returns the patch-id of every commit that has one
+func patchIDs(commits []*object.Commit) (map[*object.Commit]plumbing.Hash, error) {
+	ids := make(map[*object.Commit]plumbing.Hash, len(commits))
+
+	for _, c := range commits {
+		id, ok, err := PatchID(c)
+		if err != nil {
+			return nil, fmt.Errorf("unable to compute the patch-id of commit %s: %v", c.Hash, err)
+		}
+
+		if ok {
+			ids[c] = id
+		}
+	}
+
+	return ids, nil
+}
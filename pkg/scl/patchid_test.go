@@ -0,0 +1,85 @@
+package scl
+
+import (
+	"github.com/go-git/go-git/v5/plumbing"
+	"testing"
+)
+
+func TestPatchID(t *testing.T) {
+	tr := newTestRepo(t)
+	defer tr.Cleanup()
+
+	base := tr.CommitContents("ABC-1 base", map[string]string{"a.txt": "one\ntwo\nthree\n", "b.txt": "b\n"})
+	fix := tr.CommitContents("ABC-2 fix", map[string]string{"a.txt": "one\n2\nthree\n"})
+
+	tr.Checkout("release", base)
+	tr.CommitContents("ABC-3 other", map[string]string{"b.txt": "other b\n"})
+	// Same change with a different message, date and parent, and with different whitespaces
+	pick := tr.CommitContents("ABC-2 fix (cherry picked)", map[string]string{"a.txt": "one\n  2\nthree\n"})
+	different := tr.CommitContents("ABC-4 different", map[string]string{"a.txt": "one\n4\nthree\n"})
+	empty := tr.CommitContents("ABC-5 empty", map[string]string{})
+
+	glifRepo := tr.glifRepo()
+	patchID := func(hash plumbing.Hash) (plumbing.Hash, bool) {
+		c, err := glifRepo.GitRepo.CommitObject(hash)
+		if err != nil {
+			t.Fatalf("unable to load commit: %v", err)
+		}
+
+		id, ok, err := PatchID(c)
+		if err != nil {
+			t.Fatalf("PatchID returned an error: %v", err)
+		}
+
+		return id, ok
+	}
+
+	fixID, _ := patchID(fix)
+	if pickID, _ := patchID(pick); pickID != fixID {
+		t.Errorf("a cherry-pick should have the same patch-id. got=%s, want=%s", pickID, fixID)
+	}
+
+	if differentID, _ := patchID(different); differentID == fixID {
+		t.Errorf("a different change should have a different patch-id. got=%s", differentID)
+	}
+
+	if _, ok := patchID(empty); ok {
+		t.Errorf("a commit without changes should not have a patch-id")
+	}
+}
+
+func TestRemoveCherryPicks(t *testing.T) {
+	tr := newTestRepo(t)
+	defer tr.Cleanup()
+
+	base := tr.CommitContents("ABC-1 base", map[string]string{"a.txt": "a\n", "b.txt": "b\n", "c.txt": "c\n"})
+	tr.CommitContents("ABC-2 hotfix", map[string]string{"a.txt": "fixed a\n"})
+	head := tr.CommitContents("ABC-3 feature", map[string]string{"b.txt": "feature b\n"})
+
+	tr.Checkout("release", base)
+	tr.CommitContents("ABC-2 hotfix", map[string]string{"a.txt": "fixed a\n"})
+	release := tr.CommitContents("ABC-4 release only", map[string]string{"c.txt": "release c\n"})
+
+	glifRepo := tr.glifRepo()
+	commitRange, err := glifRepo.WalkRange(release, head)
+	if err != nil {
+		t.Fatalf("WalkRange returned an error: %v", err)
+	}
+
+	result, backported, err := RemoveCherryPicks(commitRange)
+	if err != nil {
+		t.Fatalf("RemoveCherryPicks returned an error: %v", err)
+	}
+
+	if got := commitMessages(result.ToOnly); !sameMessages(got, []string{"ABC-3 feature"}) {
+		t.Errorf("wrong ToOnly commits. got=%q", got)
+	}
+
+	if got := commitMessages(result.FromOnly); !sameMessages(got, []string{"ABC-4 release only"}) {
+		t.Errorf("wrong FromOnly commits. got=%q", got)
+	}
+
+	if got := commitMessages(backported); !sameMessages(got, []string{"ABC-2 hotfix"}) {
+		t.Errorf("wrong backported commits. got=%q", got)
+	}
+}
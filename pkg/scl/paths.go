@@ -0,0 +1,179 @@
+package scl
+
+import (
+	"fmt"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"path"
+	"strings"
+)
+
+// PathFilter restricts a diff to the commits that modified some paths of the repository (ex: one service of a
+// monorepo). The paths are relative to the root of the repository and use '/' as separator.
+// The patterns use the syntax of path.Match, with the addition of '**' which matches any number of directories:
+//	- services/service-a/**: every file under services/service-a
+//	- **/*.md: every markdown file
+type PathFilter struct {
+	Include []string // A path must match at least one of these patterns (every path matches when empty)
+	Exclude []string // A path must not match any of these patterns
+}
+
+// NewPathFilter validates the patterns and creates the filter. It returns nil if no pattern is specified.
+func NewPathFilter(include, exclude []string) (*PathFilter, error) {
+	if len(include) == 0 && len(exclude) == 0 {
+		return nil, nil
+	}
+
+	for _, pattern := range append(append([]string{}, include...), exclude...) {
+		if err := validateGlob(pattern); err != nil {
+			return nil, fmt.Errorf("invalid path pattern '%s': %v", pattern, err)
+		}
+	}
+
+	return &PathFilter{Include: include, Exclude: exclude}, nil
+}
+
+// Match returns true if the path is included and not excluded by the filter
+func (filter *PathFilter) Match(file string) bool {
+	included := len(filter.Include) == 0
+	for _, pattern := range filter.Include {
+		if matchGlob(pattern, file) {
+			included = true
+			break
+		}
+	}
+
+	if !included {
+		return false
+	}
+
+	for _, pattern := range filter.Exclude {
+		if matchGlob(pattern, file) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// FilterCommits keeps only the commits that modified at least one path matching the filter. Like 'git log -- <paths>'
+// does, a merge commit is kept only when it differs from all of its parents on the matching paths (ex: a conflict
+// resolution), the changes it brings are already accounted for by the commits of the merged branch.
+// A nil filter keeps every commit.
+func (glifRepo *GlifRepo) FilterCommits(commits []*object.Commit, filter *PathFilter) ([]*object.Commit, error) {
+	if filter == nil {
+		return commits, nil
+	}
+
+	filtered := make([]*object.Commit, 0, len(commits))
+	for _, c := range commits {
+		touched, err := touchesPaths(c, filter)
+		if err != nil {
+			return nil, fmt.Errorf("unable to compute the changes of commit %s: %v", c.Hash, err)
+		}
+
+		if touched {
+			filtered = append(filtered, c)
+		}
+	}
+
+	return filtered, nil
+}
+
+func touchesPaths(c *object.Commit, filter *PathFilter) (bool, error) {
+	tree, err := c.Tree()
+	if err != nil {
+		return false, err
+	}
+
+	if c.NumParents() == 0 {
+		return changesMatch(&object.Tree{}, tree, filter)
+	}
+
+	touched := true
+	err = c.Parents().ForEach(func(parent *object.Commit) error {
+		parentTree, err := parent.Tree()
+		if err != nil {
+			return err
+		}
+
+		match, err := changesMatch(parentTree, tree, filter)
+		if err != nil {
+			return err
+		}
+
+		touched = touched && match
+		return nil
+	})
+
+	return touched, err
+}
+
+func changesMatch(from, to *object.Tree, filter *PathFilter) (bool, error) {
+	changes, err := object.DiffTree(from, to)
+	if err != nil {
+		return false, err
+	}
+
+	for _, change := range changes {
+		// The name is empty on the side where the file does not exist, a rename matches if either location does
+		for _, name := range []string{change.From.Name, change.To.Name} {
+			if name != "" && filter.Match(name) {
+				return true, nil
+			}
+		}
+	}
+
+	return false, nil
+}
+
+// validateGlob returns path.ErrBadPattern if any segment of the pattern is malformed
+func validateGlob(pattern string) error {
+	for _, segment := range strings.Split(pattern, "/") {
+		if _, err := path.Match(segment, ""); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// matchGlob reports whether the path matches the pattern, '**' matching zero or more complete directories.
+// The pattern must have been validated with validateGlob.
+func matchGlob(pattern, file string) bool {
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(file, "/"))
+}
+
+func matchSegments(pattern, file []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			// Consecutive '**' are equivalent to a single one
+			for len(pattern) > 0 && pattern[0] == "**" {
+				pattern = pattern[1:]
+			}
+
+			if len(pattern) == 0 {
+				return true
+			}
+
+			for i := 0; i <= len(file); i++ {
+				if matchSegments(pattern, file[i:]) {
+					return true
+				}
+			}
+
+			return false
+		}
+
+		if len(file) == 0 {
+			return false
+		}
+
+		if ok, _ := path.Match(pattern[0], file[0]); !ok {
+			return false
+		}
+
+		pattern, file = pattern[1:], file[1:]
+	}
+
+	return len(file) == 0
+}
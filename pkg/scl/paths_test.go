@@ -0,0 +1,162 @@
+package scl
+
+import (
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+func TestPathFilterMatch(t *testing.T) {
+	tests := []struct {
+		include  []string
+		exclude  []string
+		file     string
+		expected bool
+	}{
+		{[]string{"services/service-a/**"}, nil, "services/service-a/main.go", true},
+		{[]string{"services/service-a/**"}, nil, "services/service-a/internal/db/db.go", true},
+		{[]string{"services/service-a/**"}, nil, "services/service-b/main.go", false},
+		{[]string{"services/service-a/**"}, nil, "services/service-ab/main.go", false},
+		{[]string{"**/*.md"}, nil, "README.md", true},
+		{[]string{"**/*.md"}, nil, "docs/api/index.md", true},
+		{[]string{"services/*/main.go"}, nil, "services/service-b/main.go", true},
+		{[]string{"services/*/main.go"}, nil, "services/service-b/cmd/main.go", false},
+		{[]string{"services/**/db/*.go"}, nil, "services/service-a/internal/db/db.go", true},
+		{nil, []string{"**/*_test.go"}, "services/service-a/main_test.go", false},
+		{nil, []string{"**/*_test.go"}, "services/service-a/main.go", true},
+		{[]string{"services/service-a/**"}, []string{"**/*.md"}, "services/service-a/README.md", false},
+	}
+
+	for _, tt := range tests {
+		filter, err := NewPathFilter(tt.include, tt.exclude)
+		if err != nil {
+			t.Fatalf("NewPathFilter returned an error: %v", err)
+		}
+
+		if got := filter.Match(tt.file); got != tt.expected {
+			t.Errorf("Match(%s) with include=%v exclude=%v is wrong. got=%t, want=%t", tt.file, tt.include, tt.exclude,
+				got, tt.expected)
+		}
+	}
+}
+
+func TestNewPathFilter(t *testing.T) {
+	if filter, err := NewPathFilter(nil, nil); filter != nil || err != nil {
+		t.Errorf("NewPathFilter without patterns should return nil. got=(%v, %v)", filter, err)
+	}
+
+	_, err := NewPathFilter([]string{"services/**"}, []string{"services/[a-"})
+	if err == nil || err.Error() != "invalid path pattern 'services/[a-': syntax error in pattern" {
+		t.Errorf("NewPathFilter should fail on a malformed pattern. got=%v", err)
+	}
+}
+
+func TestFilterCommits(t *testing.T) {
+	tr := newTestRepo(t)
+	defer tr.Cleanup()
+
+	base := tr.Commit("ABC-1 base", "services/service-a/main.go", "services/service-b/main.go")
+	tr.Commit("ABC-2 service a", "services/service-a/main.go")
+	tr.Commit("ABC-3 service b", "services/service-b/main.go")
+	tr.Commit("ABC-4 both", "services/service-a/db/db.go", "services/service-b/main.go")
+	tr.Commit("ABC-5 docs of a", "services/service-a/README.md")
+	head := tr.Commit("ABC-6 root file", "go.mod")
+
+	glifRepo := tr.glifRepo()
+
+	tests := []struct {
+		include  []string
+		exclude  []string
+		expected []string
+	}{
+		{nil, nil, []string{"ABC-6 root file", "ABC-5 docs of a", "ABC-4 both", "ABC-3 service b", "ABC-2 service a"}},
+		{[]string{"services/service-a/**"}, nil, []string{"ABC-5 docs of a", "ABC-4 both", "ABC-2 service a"}},
+		{[]string{"services/service-a/**"}, []string{"**/*.md"}, []string{"ABC-4 both", "ABC-2 service a"}},
+		{nil, []string{"services/**"}, []string{"ABC-6 root file"}},
+	}
+
+	for _, tt := range tests {
+		commitRange, err := glifRepo.WalkRange(base, head)
+		if err != nil {
+			t.Fatalf("WalkRange returned an error: %v", err)
+		}
+
+		filter, err := NewPathFilter(tt.include, tt.exclude)
+		if err != nil {
+			t.Fatalf("NewPathFilter returned an error: %v", err)
+		}
+
+		commits, err := glifRepo.FilterCommits(commitRange.Commits(DiffRange), filter)
+		if err != nil {
+			t.Fatalf("FilterCommits returned an error: %v", err)
+		}
+
+		if got := commitMessages(commits); !sameMessages(got, tt.expected) {
+			t.Errorf("include=%v exclude=%v wrong commits. got=%v, want=%v", tt.include, tt.exclude, got, tt.expected)
+		}
+	}
+}
+
+func TestFilterCommitsMerge(t *testing.T) {
+	tr := newTestRepo(t)
+	defer tr.Cleanup()
+
+	base := tr.Commit("ABC-1 base", "services/service-a/main.go")
+	feature := tr.Commit("ABC-2 service a", "services/service-a/main.go")
+
+	tr.Checkout("main", base)
+	main := tr.Commit("ABC-3 other", "other.txt")
+
+	w, err := tr.GitRepo.Worktree()
+	if err != nil {
+		t.Fatalf("unable to get worktree: %v", err)
+	}
+
+	merge := func(content string) *object.Commit {
+		path := filepath.Join(tr.Dir, "services", "service-a", "main.go")
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("unable to write the merge: %v", err)
+		}
+		if _, err := w.Add("services/service-a/main.go"); err != nil {
+			t.Fatalf("unable to add the merge: %v", err)
+		}
+
+		opts := &git.CommitOptions{Author: tr.Signature(), Parents: []plumbing.Hash{main, feature}}
+		hash, err := w.Commit("Merge feature", opts)
+		if err != nil {
+			t.Fatalf("unable to commit the merge: %v", err)
+		}
+
+		c, err := tr.GitRepo.CommitObject(hash)
+		if err != nil {
+			t.Fatalf("unable to load the merge: %v", err)
+		}
+
+		return c
+	}
+
+	tests := []struct {
+		merge    *object.Commit
+		expected int
+	}{
+		// Same content as the feature branch: the changes are already reported by 'ABC-2 service a'
+		{merge("ABC-2 service a\n"), 0},
+		// The merge modified service-a compared to both of its parents (ex: conflict resolution)
+		{merge("ABC-2 service a\nresolved\n"), 1},
+	}
+
+	filter, _ := NewPathFilter([]string{"services/**"}, nil)
+	for i, tt := range tests {
+		commits, err := tr.glifRepo().FilterCommits([]*object.Commit{tt.merge}, filter)
+		if err != nil {
+			t.Fatalf("FilterCommits returned an error: %v", err)
+		}
+
+		if len(commits) != tt.expected {
+			t.Errorf("merge %d: wrong number of commits. got=%d, want=%d", i, len(commits), tt.expected)
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package scl
+
+import (
+	"fmt"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+)
+
+// PushTags does a 'Push' of the tags to the remote (the 'origin' remote by default). A remote that already has the
+// tags is not considered an error. The authentication is optional.
+func (glifRepo *GlifRepo) PushTags(remoteName string, auth transport.AuthMethod, names ...string) error {
+	if remoteName == "" {
+		remoteName = git.DefaultRemoteName
+	}
+
+	refSpecs := make([]config.RefSpec, 0, len(names))
+	for _, name := range names {
+		ref := plumbing.NewTagReferenceName(name)
+		refSpecs = append(refSpecs, config.RefSpec(ref+":"+ref))
+	}
+
+	err := glifRepo.GitRepo.Push(&git.PushOptions{RemoteName: remoteName, RefSpecs: refSpecs, Auth: auth})
+	if err != nil && err != git.NoErrAlreadyUpToDate {
+		return fmt.Errorf("unable to push the tags to remote '%s': %v", remoteName, err)
+	}
+
+	return nil
+}
@@ -7,13 +7,12 @@
 package scl
 
 import (
+	"bytes"
 	"fmt"
 	"github.com/TurnsCoffeeIntoScripts/git-log-issue-finder/pkg/configuration"
-	"github.com/TurnsCoffeeIntoScripts/git-log-issue-finder/pkg/helpers"
 	"github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/plumbing"
-	"github.com/go-git/go-git/v5/plumbing/object"
-	"log"
+	"github.com/go-git/go-git/v5/plumbing/transport"
 	"regexp"
 	"sort"
 )
@@ -25,92 +24,158 @@ type GlifRepo struct {
 	HeadRef *plumbing.Reference
 	GitRepo *git.Repository
 
-	matchingTags         map[string]*object.Tag // Map that take the tag name as index to match the tag object (*object.Tag)
-	tagsLatestToEarliest []*object.Tag          // This slice is ordered from latest to earliest. Meaning that the tag at index 0 is the very latest.
+	matchingTags         map[string]*GlifTag // Map that take the tag name as index to match the tag object (*GlifTag)
+	tagsLatestToEarliest []*GlifTag          // This slice is ordered from latest to earliest. Meaning that the tag at index 0 is the very latest.
 }
 
-// Open does a 'PlainOpen' on the *git.Repository and will create the map and slice of *object.Tag.
-func (glifRepo *GlifRepo) Open(repoLoc string) {
+// Open does a 'PlainOpen' on the *git.Repository and will create the map and slice of *GlifTag.
+func (glifRepo *GlifRepo) Open(repoLoc string) error {
 	repo, err := git.PlainOpen(repoLoc)
 	if err != nil {
-		fmt.Println(err.Error())
-		log.Fatal("an error occured while instantiating a new repository object")
+		return fmt.Errorf("unable to open repository at '%s': %v", repoLoc, err)
 	}
 
 	glifRepo.GitRepo = repo
-	glifRepo.matchingTags = make(map[string]*object.Tag)
-	glifRepo.tagsLatestToEarliest = make([]*object.Tag, 0)
+	glifRepo.matchingTags = make(map[string]*GlifTag)
+	glifRepo.tagsLatestToEarliest = make([]*GlifTag, 0)
+
+	return nil
 }
 
-// Fetch does a 'Fetch' on the *git.Repository.
-func (glifRepo *GlifRepo) Fetch() {
-	if fetchErr := glifRepo.GitRepo.Fetch(&git.FetchOptions{}); fetchErr != nil {
-		fmt.Println(fetchErr.Error())
-		log.Fatal("an error occured while trying to fetch repo. Continuing without fetching...")
+// Clone does a 'PlainClone' of the repository at the url (or path) in the directory, with every tag, then initializes
+// the GlifRepo like Open. The clone is a bare repository: the history and the tags are read without checking the
+// files out. The authentication is optional.
+func (glifRepo *GlifRepo) Clone(url, dir string, auth transport.AuthMethod) error {
+	repo, err := git.PlainClone(dir, true, &git.CloneOptions{URL: url, Auth: auth, Tags: git.AllTags})
+	if err != nil {
+		return fmt.Errorf("unable to clone repository '%s': %v", url, err)
 	}
+
+	glifRepo.GitRepo = repo
+	glifRepo.matchingTags = make(map[string]*GlifTag)
+	glifRepo.tagsLatestToEarliest = make([]*GlifTag, 0)
+
+	return nil
 }
 
-// InitHeadRef does a 'Head' on the *git.Repository. This will return a *plumbing.Reference corresponding to the
-// git 'HEAD' reference.
-func (glifRepo *GlifRepo) InitHeadRef() {
+// InitHeadRef does a 'Head' on the *git.Repository. This will set HeadRef to the *plumbing.Reference corresponding to
+// the git 'HEAD' reference.
+func (glifRepo *GlifRepo) InitHeadRef() error {
 	ref, err := glifRepo.GitRepo.Head()
 	if err != nil {
-		fmt.Print(err)
-		log.Fatal("an error occured while retrieving the HEAD reference")
+		return fmt.Errorf("unable to retrieve the HEAD reference: %v", err)
 	}
 
 	glifRepo.HeadRef = ref
+
+	return nil
 }
 
 // FetchAllMatchingTags uses a regex to match tags (by name) in the git repository.
+// Both annotated and lightweight tags are matched. With OrderByDate the tags are ordered using the tagger date, or the
+// committer date of the tagged commit when the tag is a lightweight tag. With OrderBySemver they are ordered using the
+// numbers captured by the groups of the regex (see parseTagVersion). Each call replaces the tags of the previous one.
 // Used in the following builtin(s):
 //	- extractTags
-func (glifRepo *GlifRepo) FetchAllMatchingTags(regexString string) bool {
-	r, _ := regexp.Compile(regexString)
+func (glifRepo *GlifRepo) FetchAllMatchingTags(regexString string, order TagOrder) error {
+	r, err := regexp.Compile(regexString)
+	if err != nil {
+		return fmt.Errorf("invalid tag pattern '%s': %v", regexString, err)
+	}
 
 	iter, err := glifRepo.GitRepo.Tags()
 
 	if err != nil {
-		return false
+		return fmt.Errorf("unable to list the tags: %v", err)
 	}
 
+	// The tags of a previous call (possibly with another format) aren't kept
+	glifRepo.matchingTags = make(map[string]*GlifTag)
+
 	_ = iter.ForEach(func(reference *plumbing.Reference) error {
-		tagObj, err := glifRepo.GitRepo.TagObject(reference.Hash())
-		if err == nil && tagObj != nil {
-			if r.MatchString(reference.Name().String()) {
-				// TODO: Uncomment the following line if needed when debugging
-				//fmt.Printf("Matched tag name (tagged object): %s (%s)\n", reference.Name(), tagObj.Name)
-				glifRepo.matchingTags[reference.Name().String()] = tagObj
-			}
+		if !r.MatchString(reference.Name().String()) {
+			return nil
+		}
+
+		tag, err := glifRepo.resolveTag(reference)
+		if err == nil {
+			// TODO: Uncomment the following line if needed when debugging
+			//fmt.Printf("Matched tag name (tagged commit): %s (%s)\n", reference.Name(), tag.Commit.Hash)
+			tag.Name = reference.Name().String()
+			glifRepo.matchingTags[tag.Name] = tag
 		}
 		// TODO: Uncomment the following line if needed when debugging
 		/*else {
-			fmt.Printf("No tag object found for: %s (%s)\n", reference.Name(), reference.Target())
+			fmt.Printf("No commit found for: %s (%s)\n", reference.Name(), reference.Target())
 		}*/
 
 		return nil
 	})
 
-	for name, reference := range glifRepo.matchingTags {
-		reference.Name = name
-		if _, alreadyIn := helpers.Contains(glifRepo.tagsLatestToEarliest, name); !alreadyIn {
-			glifRepo.tagsLatestToEarliest = append(glifRepo.tagsLatestToEarliest, reference)
+	glifRepo.tagsLatestToEarliest = make([]*GlifTag, 0, len(glifRepo.matchingTags))
+	for _, tag := range glifRepo.matchingTags {
+		glifRepo.tagsLatestToEarliest = append(glifRepo.tagsLatestToEarliest, tag)
+	}
+
+	switch order {
+	case OrderBySemver:
+		versions := make(map[*GlifTag]tagVersion, len(glifRepo.tagsLatestToEarliest))
+		for _, tag := range glifRepo.tagsLatestToEarliest {
+			versions[tag] = parseTagVersion(r, tag.Name)
 		}
+
+		sort.SliceStable(glifRepo.tagsLatestToEarliest, func(i, j int) bool {
+			return compareTagVersions(
+				versions[glifRepo.tagsLatestToEarliest[i]],
+				versions[glifRepo.tagsLatestToEarliest[j]]) > 0
+		})
+	default:
+		sort.SliceStable(glifRepo.tagsLatestToEarliest, func(i, j int) bool {
+			return glifRepo.tagsLatestToEarliest[i].When().After(
+				glifRepo.tagsLatestToEarliest[j].When())
+		})
 	}
 
-	sort.SliceStable(glifRepo.tagsLatestToEarliest, func(i, j int) bool {
-		return glifRepo.tagsLatestToEarliest[i].Tagger.When.After(
-			glifRepo.tagsLatestToEarliest[j].Tagger.When)
-	})
+	return nil
+}
+
+// TagFormatRegex converts a tag format to the regex matching the names of its tags:
+//	- '$' is a number (ex: '$.$.$' matches '1.12.0')
+//	- '*' is any sequence of characters, '+' a non empty one
+//	- '.' is a dot, every other character matches itself
+// Used in the following builtin(s):
+//	- extractTags
+func TagFormatRegex(format string) string {
+	var buffer bytes.Buffer
+	for _, c := range format {
+		switch c {
+		case '$':
+			buffer.WriteString("([0-9]+)")
+		case '.':
+			buffer.WriteString("\\.")
+		case '*':
+			buffer.WriteString(".*")
+		case '+':
+			buffer.WriteString(".+")
+		default:
+			buffer.WriteRune(c)
+		}
+	}
 
-	return true
+	buffer.WriteString("$")
+	return buffer.String()
 }
 
-// GetLatestTag returns the appropriate *object.Tag that correspond to the specified offset.
+// MatchingTags returns the tags matched by FetchAllMatchingTags, from the latest to the earliest
+func (glifRepo *GlifRepo) MatchingTags() []*GlifTag {
+	return glifRepo.tagsLatestToEarliest
+}
+
+// GetLatestTag returns the appropriate *GlifTag that correspond to the specified offset.
 // GetLatestTag(0) would return the very latest tag because it would return the first index of the slice 'tagsLatestToEarliest'
 // Used in the following builtin(s):
 //	- getLatestTag
-func (glifRepo *GlifRepo) GetLatestTag(offset int64) *object.Tag {
+func (glifRepo *GlifRepo) GetLatestTag(offset int64) *GlifTag {
 	if int(offset) >= len(glifRepo.tagsLatestToEarliest) {
 		return nil
 	}
@@ -118,32 +183,36 @@ func (glifRepo *GlifRepo) GetLatestTag(offset int64) *object.Tag {
 	return glifRepo.tagsLatestToEarliest[offset]
 }
 
-// GetSpecificTag returns the appropriate *object.Tag that correspond to the specified name
-func (glifRepo *GlifRepo) GetSpecificTag(tagName string) *object.Tag {
-	tagBuffer := make(map[string]*object.Tag, 0)
+// GetSpecificTag returns the appropriate *GlifTag that correspond to the specified name (a regex), nil if there is
+// none. An error is returned when the name is not a valid regex.
+func (glifRepo *GlifRepo) GetSpecificTag(tagName string) (*GlifTag, error) {
+	tagBuffer := make(map[string]*GlifTag, 0)
 
-	r, _ := regexp.Compile(".*" + tagName + ".*")
+	r, err := regexp.Compile(".*" + tagName + ".*")
+	if err != nil {
+		return nil, fmt.Errorf("invalid tag pattern '%s': %v", tagName, err)
+	}
 
 	iter, err := glifRepo.GitRepo.Tags()
 
 	if err != nil {
-		return nil
+		return nil, fmt.Errorf("unable to list the tags: %v", err)
 	}
 
 	_ = iter.ForEach(func(reference *plumbing.Reference) error {
-		tagObj, err := glifRepo.GitRepo.TagObject(reference.Hash())
-		if err == nil && tagObj != nil {
-			if r.MatchString(reference.Name().String()) {
-				tagBuffer[tagName] = tagObj
-				return nil
-			}
+		if !r.MatchString(reference.Name().String()) {
+			return nil
+		}
+
+		if tag, err := glifRepo.resolveTag(reference); err == nil {
+			tagBuffer[tagName] = tag
 		}
 		return nil
 	})
 
 	if len(tagBuffer) == 1 {
-		return tagBuffer[tagName]
+		return tagBuffer[tagName], nil
 	}
 
-	return nil
+	return nil, nil
 }
@@ -0,0 +1,210 @@
+package scl
+
+import (
+	"github.com/TurnsCoffeeIntoScripts/git-log-issue-finder/pkg/internal/testrepo"
+	"github.com/go-git/go-git/v5/plumbing"
+	"testing"
+)
+
+// testRepo is a repository of the tests (see testrepo.Repository) that can be opened as a GlifRepo
+type testRepo struct {
+	*testrepo.Repository
+	t *testing.T
+}
+
+func newTestRepo(t *testing.T) *testRepo {
+	return &testRepo{Repository: testrepo.New(t), t: t}
+}
+
+func (tr *testRepo) glifRepo() *GlifRepo {
+	glifRepo := &GlifRepo{}
+	if err := glifRepo.Open(tr.Dir); err != nil {
+		tr.t.Fatalf("unable to open repository: %v", err)
+	}
+
+	return glifRepo
+}
+
+func TestFetchAllMatchingTagsWithLightweightTags(t *testing.T) {
+	tr := newTestRepo(t)
+	defer tr.Cleanup()
+
+	first := tr.Commit("ABC-1 first")
+	tr.LightweightTag("1.0.0", first)
+	second := tr.Commit("ABC-2 second")
+	tr.AnnotatedTag("1.1.0", second)
+	third := tr.Commit("ABC-3 third")
+	tr.LightweightTag("1.2.0", third)
+	tr.LightweightTag("not-a-version", third)
+
+	glifRepo := tr.glifRepo()
+	if err := glifRepo.FetchAllMatchingTags("([0-9]+)\\.([0-9]+)\\.([0-9]+)$", OrderByDate); err != nil {
+		t.Fatalf("FetchAllMatchingTags returned an error: %v", err)
+	}
+
+	expected := []struct {
+		name      string
+		commit    plumbing.Hash
+		annotated bool
+	}{
+		{"refs/tags/1.2.0", third, false},
+		{"refs/tags/1.1.0", second, true},
+		{"refs/tags/1.0.0", first, false},
+	}
+
+	for i, tt := range expected {
+		tag := glifRepo.GetLatestTag(int64(i))
+		if tag == nil {
+			t.Fatalf("GetLatestTag(%d) returned nil", i)
+		}
+
+		if tag.Name != tt.name {
+			t.Errorf("GetLatestTag(%d) has wrong name. got=%s, want=%s", i, tag.Name, tt.name)
+		}
+
+		if tag.Commit.Hash != tt.commit {
+			t.Errorf("GetLatestTag(%d) has wrong commit. got=%s, want=%s", i, tag.Commit.Hash, tt.commit)
+		}
+
+		if tag.IsAnnotated() != tt.annotated {
+			t.Errorf("GetLatestTag(%d) has wrong annotation. got=%t, want=%t", i, tag.IsAnnotated(), tt.annotated)
+		}
+	}
+
+	if tag := glifRepo.GetLatestTag(int64(len(expected))); tag != nil {
+		t.Errorf("GetLatestTag(%d) should be nil. got=%s", len(expected), tag.Name)
+	}
+}
+
+func TestGetSpecificTagWithLightweightTag(t *testing.T) {
+	tr := newTestRepo(t)
+	defer tr.Cleanup()
+
+	first := tr.Commit("ABC-1 first")
+	tr.LightweightTag("1.0.0", first)
+
+	tag, err := tr.glifRepo().GetSpecificTag("1.0.0")
+	if err != nil {
+		t.Fatalf("GetSpecificTag returned an error: %v", err)
+	}
+
+	if tag == nil {
+		t.Fatalf("GetSpecificTag returned nil")
+	}
+
+	if tag.Commit.Hash != first {
+		t.Errorf("tag has wrong commit. got=%s, want=%s", tag.Commit.Hash, first)
+	}
+
+	if !tag.When().Equal(tag.Commit.Committer.When) {
+		t.Errorf("lightweight tag should use the committer date. got=%s, want=%s", tag.When(), tag.Commit.Committer.When)
+	}
+}
+
+func TestInvalidTagPatterns(t *testing.T) {
+	tr := newTestRepo(t)
+	defer tr.Cleanup()
+
+	tr.LightweightTag("1.0.0", tr.Commit("ABC-1 first"))
+	glifRepo := tr.glifRepo()
+
+	err := glifRepo.FetchAllMatchingTags("([0-9]+\\.", OrderByDate)
+	expected := "invalid tag pattern '([0-9]+\\.': error parsing regexp: missing closing ): `([0-9]+\\.`"
+	if err == nil || err.Error() != expected {
+		t.Errorf("wrong error from FetchAllMatchingTags. got=%v, want=%q", err, expected)
+	}
+
+	tag, err := glifRepo.GetSpecificTag("1.0.0[")
+	expected = "invalid tag pattern '1.0.0[': error parsing regexp: missing closing ]: `[.*`"
+	if tag != nil || err == nil || err.Error() != expected {
+		t.Errorf("wrong result from GetSpecificTag. got=(%v, %v), want=(nil, %q)", tag, err, expected)
+	}
+}
+
+func TestFetchAllMatchingTagsOrderBySemver(t *testing.T) {
+	tr := newTestRepo(t)
+	defer tr.Cleanup()
+
+	tr.AnnotatedTag("1.3.0", tr.Commit("ABC-1 first"))
+	tr.AnnotatedTag("1.4.0", tr.Commit("ABC-2 second"))
+	// Hotfix on the previous release, tagged after 1.4.0
+	tr.AnnotatedTag("1.3.5", tr.Commit("ABC-3 hotfix"))
+
+	tests := []struct {
+		order    TagOrder
+		expected []string
+	}{
+		{OrderByDate, []string{"refs/tags/1.3.5", "refs/tags/1.4.0", "refs/tags/1.3.0"}},
+		{OrderBySemver, []string{"refs/tags/1.4.0", "refs/tags/1.3.5", "refs/tags/1.3.0"}},
+	}
+
+	for _, tt := range tests {
+		glifRepo := tr.glifRepo()
+		if err := glifRepo.FetchAllMatchingTags("([0-9]+)\\.([0-9]+)\\.([0-9]+)$", tt.order); err != nil {
+			t.Fatalf("FetchAllMatchingTags returned an error: %v", err)
+		}
+
+		for i, name := range tt.expected {
+			tag := glifRepo.GetLatestTag(int64(i))
+			if tag == nil || tag.Name != name {
+				t.Errorf("order %d: GetLatestTag(%d) is wrong. got=%v, want=%s", tt.order, i, tag, name)
+			}
+		}
+	}
+}
+
+func TestFetchAllMatchingTagsTwice(t *testing.T) {
+	tr := newTestRepo(t)
+	defer tr.Cleanup()
+
+	tr.AnnotatedTag("1.3.0", tr.Commit("ABC-1 first"))
+	tr.AnnotatedTag("v2", tr.Commit("ABC-2 second"))
+	tr.AnnotatedTag("1.4.0", tr.Commit("ABC-3 third"))
+
+	glifRepo := tr.glifRepo()
+	if err := glifRepo.FetchAllMatchingTags("v([0-9]+)$", OrderBySemver); err != nil {
+		t.Fatalf("FetchAllMatchingTags returned an error: %v", err)
+	}
+
+	// The tags of the first format aren't matched by the second one
+	if err := glifRepo.FetchAllMatchingTags("([0-9]+)\\.([0-9]+)\\.([0-9]+)$", OrderBySemver); err != nil {
+		t.Fatalf("FetchAllMatchingTags returned an error: %v", err)
+	}
+
+	expected := []string{"refs/tags/1.4.0", "refs/tags/1.3.0"}
+	tags := glifRepo.MatchingTags()
+	if len(tags) != len(expected) {
+		t.Fatalf("wrong number of tags. got=%d, want=%d", len(tags), len(expected))
+	}
+
+	for i, name := range expected {
+		if tags[i].Name != name {
+			t.Errorf("wrong tag at index %d. got=%s, want=%s", i, tags[i].Name, name)
+		}
+	}
+}
+
+func TestOpenAndInitHeadRefErrors(t *testing.T) {
+	glifRepo := &GlifRepo{}
+	if err := glifRepo.Open("/this/path/does/not/exist"); err == nil {
+		t.Errorf("Open should fail on a path that is not a repository")
+	}
+
+	tr := newTestRepo(t)
+	defer tr.Cleanup()
+
+	// A repository without any commit has no HEAD to resolve
+	glifRepo = tr.glifRepo()
+	if err := glifRepo.InitHeadRef(); err == nil {
+		t.Errorf("InitHeadRef should fail on a repository without commits")
+	}
+
+	head := tr.Commit("ABC-1 first")
+	if err := glifRepo.InitHeadRef(); err != nil {
+		t.Fatalf("InitHeadRef returned an error: %v", err)
+	}
+
+	if glifRepo.HeadRef.Hash() != head {
+		t.Errorf("HeadRef has wrong hash. got=%s, want=%s", glifRepo.HeadRef.Hash(), head)
+	}
+}
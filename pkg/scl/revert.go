@@ -0,0 +1,110 @@
+package scl
+
+import (
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"regexp"
+	"strings"
+)
+
+// The two ways a revert commit designates the commit it reverts (see 'git revert'):
+//	- the body contains "This reverts commit <hash>."
+//	- the subject is 'Revert "<subject of the reverted commit>"'
+var (
+	revertBodyRegex    = regexp.MustCompile(`This reverts commit ([0-9a-fA-F]{7,40})`)
+	revertSubjectRegex = regexp.MustCompile(`^Revert "(.*)"$`)
+)
+
+// RevertTarget returns the hash (possibly abbreviated) and the subject of the commit reverted by the specified
+// commit. Both are empty if the commit is not a revert, the hash is empty if the body does not mention it.
+func RevertTarget(c *object.Commit) (hash string, subject string) {
+	commitSubject, body := SplitMessage(c.Message)
+
+	if match := revertSubjectRegex.FindStringSubmatch(commitSubject); match != nil {
+		subject = match[1]
+	}
+
+	if match := revertBodyRegex.FindStringSubmatch(body); match != nil {
+		hash = strings.ToLower(match[1])
+	}
+
+	return hash, subject
+}
+
+// RevertedCommits contains the result of CancelReverts
+type RevertedCommits struct {
+	Kept     []*object.Commit // Commits that are still applied at the end of the range
+	Reverted []*object.Commit // Commits that were reverted within the range (the revert commits are not included)
+}
+
+// CancelReverts removes the commits that are reverted by another commit of the same list, along with the revert
+// commits themselves. A revert of a commit that is not in the list is kept (it reverts something that was already
+// released). Reverting a revert applies the original commit again, in which case only the original commit is kept.
+// The commits must be in the order of the walk (see WalkRange), where a commit always comes before its parents, and
+// this order is preserved.
+func CancelReverts(commits []*object.Commit) *RevertedCommits {
+	// The commits are processed from the oldest to the most recent, a revert always comes after the reverted commit.
+	// The committer dates are not used: they have a one second resolution and a commit is often reverted within the
+	// same second by scripts.
+	chronological := make([]*object.Commit, len(commits))
+	for i, c := range commits {
+		chronological[len(commits)-1-i] = c
+	}
+
+	cancelled := make(map[*object.Commit]bool)
+	revertOf := make(map[*object.Commit]*object.Commit)
+	seen := make([]*object.Commit, 0, len(chronological))
+
+	for _, c := range chronological {
+		if target := findRevertTarget(c, seen); target != nil {
+			if !cancelled[target] {
+				cancelled[target], cancelled[c] = true, true
+				revertOf[c] = target
+			} else if original, ok := revertOf[target]; ok {
+				// Revert of a revert: the original commit is applied again
+				cancelled[original], cancelled[c] = false, true
+				revertOf[c] = target
+			}
+		}
+
+		seen = append(seen, c)
+	}
+
+	result := &RevertedCommits{Kept: make([]*object.Commit, 0, len(commits)), Reverted: make([]*object.Commit, 0)}
+	for _, c := range commits {
+		if !cancelled[c] {
+			result.Kept = append(result.Kept, c)
+		} else if _, isRevert := revertOf[c]; !isRevert {
+			result.Reverted = append(result.Reverted, c)
+		}
+	}
+
+	return result
+}
+
+// findRevertTarget returns the commit reverted by 'c' amongst the commits that precede it, preferring the hash of the
+// body over the subject. It returns nil if 'c' is not a revert or if the reverted commit is not found.
+func findRevertTarget(c *object.Commit, previous []*object.Commit) *object.Commit {
+	hash, subject := RevertTarget(c)
+	if hash == "" && subject == "" {
+		return nil
+	}
+
+	if hash != "" {
+		for _, p := range previous {
+			if strings.HasPrefix(p.Hash.String(), hash) {
+				return p
+			}
+		}
+
+		return nil
+	}
+
+	// Without a hash, the most recent commit having the reverted subject is the one reverted
+	for i := len(previous) - 1; i >= 0; i-- {
+		if s, _ := SplitMessage(previous[i].Message); s == subject {
+			return previous[i]
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,113 @@
+package scl
+
+import (
+	"github.com/go-git/go-git/v5/plumbing"
+	"testing"
+	"time"
+)
+
+func TestRevertTarget(t *testing.T) {
+	g := newGraphBuilder(t)
+
+	tests := []struct {
+		message string
+		hash    string
+		subject string
+	}{
+		{"ABC-1 feature", "", ""},
+		{"Revert \"ABC-1 feature\"\n\nThis reverts commit 0123456789ABCDEF0123456789abcdef01234567.\n",
+			"0123456789abcdef0123456789abcdef01234567", "ABC-1 feature"},
+		{"Revert \"ABC-1 feature\"", "", "ABC-1 feature"},
+		{"Undo the feature\n\nThis reverts commit 0123456.", "0123456", ""},
+		{"Revert the configuration of ABC-1", "", ""},
+	}
+
+	for _, tt := range tests {
+		c, err := g.repo.CommitObject(g.commit(tt.message))
+		if err != nil {
+			t.Fatalf("unable to load commit: %v", err)
+		}
+
+		hash, subject := RevertTarget(c)
+		if hash != tt.hash || subject != tt.subject {
+			t.Errorf("RevertTarget(%q) is wrong. got=(%q, %q), want=(%q, %q)", tt.message, hash, subject, tt.hash, tt.subject)
+		}
+	}
+}
+
+func TestCancelReverts(t *testing.T) {
+	g := newGraphBuilder(t)
+
+	released := g.commit("ABC-1 released before")
+	base := g.commit("ABC-2 base", released)
+	feature := g.commit("ABC-3 feature", base)
+	other := g.commit("ABC-4 other", feature)
+	revertFeature := g.commit("Revert \"ABC-3 feature\"\n\nThis reverts commit "+feature.String()+".", other)
+	revertReleased := g.commit("Revert \"ABC-1 released before\"\n\nThis reverts commit "+released.String()+".", revertFeature)
+	bySubject := g.commit("ABC-5 by subject", revertReleased)
+	revertBySubject := g.commit("Revert \"ABC-5 by subject\"", bySubject)
+	again := g.commit("ABC-6 again", revertBySubject)
+	revertAgain := g.commit("Revert \"ABC-6 again\"\n\nThis reverts commit "+again.String()[:7]+".", again)
+	revertRevert := g.commit("Revert \"Revert \"ABC-6 again\"\"\n\nThis reverts commit "+revertAgain.String()+".", revertAgain)
+
+	commitRange, err := g.glifRepo().WalkRange(base, revertRevert)
+	if err != nil {
+		t.Fatalf("WalkRange returned an error: %v", err)
+	}
+
+	result := CancelReverts(commitRange.Commits(DiffRange))
+
+	expectedKept := []string{
+		"ABC-6 again",
+		"Revert \"ABC-1 released before\"\n\nThis reverts commit " + released.String() + ".",
+		"ABC-4 other",
+	}
+	if got := commitMessages(result.Kept); !sameMessages(got, expectedKept) {
+		t.Errorf("wrong kept commits. got=%q, want=%q", got, expectedKept)
+	}
+
+	expectedReverted := []string{"ABC-5 by subject", "ABC-3 feature"}
+	if got := commitMessages(result.Reverted); !sameMessages(got, expectedReverted) {
+		t.Errorf("wrong reverted commits. got=%q, want=%q", got, expectedReverted)
+	}
+
+	if result := CancelReverts(commitRange.Commits(DiffRange)[:0]); len(result.Kept) != 0 || len(result.Reverted) != 0 {
+		t.Errorf("CancelReverts of no commit should be empty. got=%v", result)
+	}
+}
+
+func TestCancelRevertsSameSecond(t *testing.T) {
+	g := newGraphBuilder(t)
+
+	// Every commit has the same committer date
+	when := g.clock
+	commit := func(message string, parents ...plumbing.Hash) plumbing.Hash {
+		g.clock = when.Add(-time.Minute)
+		return g.commit(message, parents...)
+	}
+
+	base := commit("ABC-1 base")
+	feature := commit("ABC-2 feature", base)
+	revertFeature := commit("Revert \"ABC-2 feature\"\n\nThis reverts commit "+feature.String()+".", feature)
+	other := commit("ABC-3 other", revertFeature)
+	revertOther := commit("Revert \"ABC-3 other\"", other)
+	revertRevert := commit("Revert \"Revert \"ABC-3 other\"\"\n\nThis reverts commit "+revertOther.String()+".",
+		revertOther)
+
+	commitRange, err := g.glifRepo().WalkRange(base, revertRevert)
+	if err != nil {
+		t.Fatalf("WalkRange returned an error: %v", err)
+	}
+
+	result := CancelReverts(commitRange.Commits(DiffRange))
+
+	expectedKept := []string{"ABC-3 other"}
+	if got := commitMessages(result.Kept); !sameMessages(got, expectedKept) {
+		t.Errorf("wrong kept commits. got=%q, want=%q", got, expectedKept)
+	}
+
+	expectedReverted := []string{"ABC-2 feature"}
+	if got := commitMessages(result.Reverted); !sameMessages(got, expectedReverted) {
+		t.Errorf("wrong reverted commits. got=%q, want=%q", got, expectedReverted)
+	}
+}
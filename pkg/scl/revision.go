@@ -0,0 +1,41 @@
+package scl
+
+import (
+	"fmt"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"strings"
+)
+
+// peelSuffixes are the revision suffixes that peel a tag to the object it points to. Since a revision is always
+// resolved to a commit they can be ignored (the go-git revision parser does not support them).
+var peelSuffixes = []string{"^{}", "^{commit}"}
+
+// ResolveRevision returns the commit designated by a git revision: a branch, a tag, a commit hash, HEAD or any
+// revision supported by go-git such as 'main~3', 'HEAD^2' or 'v1.2.0^{}'.
+// Used in the following builtin(s):
+//	- getRevision
+func (glifRepo *GlifRepo) ResolveRevision(revision string) (*object.Commit, error) {
+	rev := revision
+	for trimmed := true; trimmed; {
+		trimmed = false
+		for _, suffix := range peelSuffixes {
+			if strings.HasSuffix(rev, suffix) {
+				rev = strings.TrimSuffix(rev, suffix)
+				trimmed = true
+			}
+		}
+	}
+
+	hash, err := glifRepo.GitRepo.ResolveRevision(plumbing.Revision(rev))
+	if err != nil {
+		return nil, fmt.Errorf("unable to resolve revision '%s': %v", revision, err)
+	}
+
+	commit, err := glifRepo.GitRepo.CommitObject(*hash)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load commit of revision '%s': %v", revision, err)
+	}
+
+	return commit, nil
+}
@@ -0,0 +1,56 @@
+package scl
+
+import (
+	"github.com/go-git/go-git/v5/plumbing"
+	"testing"
+)
+
+func TestResolveRevision(t *testing.T) {
+	tr := newTestRepo(t)
+	defer tr.Cleanup()
+
+	first := tr.Commit("ABC-1 first")
+	tr.AnnotatedTag("1.0.0", first)
+	second := tr.Commit("ABC-2 second")
+	tr.LightweightTag("1.1.0", second)
+	third := tr.Commit("ABC-3 third")
+
+	release := plumbing.NewBranchReferenceName("release")
+	if err := tr.GitRepo.Storer.SetReference(plumbing.NewHashReference(release, second)); err != nil {
+		t.Fatalf("unable to create branch: %v", err)
+	}
+
+	tests := []struct {
+		revision string
+		expected plumbing.Hash
+	}{
+		{"HEAD", third},
+		{"master", third},
+		{"master~2", first},
+		{"HEAD^", second},
+		{"release", second},
+		{"1.0.0", first},
+		{"1.0.0^{}", first},
+		{"1.1.0^{commit}", second},
+		{first.String(), first},
+	}
+
+	glifRepo := tr.glifRepo()
+	for _, tt := range tests {
+		commit, err := glifRepo.ResolveRevision(tt.revision)
+		if err != nil {
+			t.Errorf("ResolveRevision(%s) returned an error: %v", tt.revision, err)
+			continue
+		}
+
+		if commit.Hash != tt.expected {
+			t.Errorf("ResolveRevision(%s) wrong commit. got=%s, want=%s", tt.revision, commit.Hash, tt.expected)
+		}
+	}
+
+	for _, revision := range []string{"unknown", "master~10"} {
+		if _, err := glifRepo.ResolveRevision(revision); err == nil {
+			t.Errorf("ResolveRevision(%s) should have failed", revision)
+		}
+	}
+}
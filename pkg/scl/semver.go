@@ -0,0 +1,160 @@
+package scl
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// TagOrder defines how the tags matched by FetchAllMatchingTags are ordered
+type TagOrder int
+
+// Definition of the supported tag orders
+//	- OrderByDate: tagger date (or committer date for lightweight tags), latest first
+//	- OrderBySemver: semantic version precedence (https://semver.org/spec/v2.0.0.html), highest first
+const (
+	OrderByDate TagOrder = iota
+	OrderBySemver
+)
+
+// ParseTagOrder converts the name of an order ("date" or "semver") to its TagOrder
+func ParseTagOrder(name string) (TagOrder, error) {
+	switch name {
+	case "date":
+		return OrderByDate, nil
+	case "semver":
+		return OrderBySemver, nil
+	default:
+		return OrderByDate, fmt.Errorf("unknown tag order '%s' (expected 'date' or 'semver')", name)
+	}
+}
+
+// tagVersion holds the numeric groups captured in a tag name along with its pre-release identifiers
+type tagVersion struct {
+	numbers    []string
+	preRelease []string
+}
+
+// parseTagVersion extracts the version of a tag name using the capturing groups of the regex used to match it.
+// Every captured group is a number (the '$' placeholders of the 'extractTags' format). The pre-release is whatever
+// follows the first three groups (MAJOR.MINOR.PATCH) when it starts with a '-', up to the build metadata ('+').
+func parseTagVersion(r *regexp.Regexp, name string) tagVersion {
+	version := tagVersion{}
+
+	indexes := r.FindStringSubmatchIndex(name)
+	if indexes == nil {
+		return version
+	}
+
+	coreEnd := -1
+	for group := 1; group < len(indexes)/2; group++ {
+		start, end := indexes[2*group], indexes[2*group+1]
+		if start < 0 {
+			continue
+		}
+
+		version.numbers = append(version.numbers, name[start:end])
+		if group <= 3 {
+			coreEnd = end
+		}
+	}
+
+	if coreEnd >= 0 && strings.HasPrefix(name[coreEnd:], "-") {
+		preRelease := name[coreEnd+1:]
+		if idx := strings.Index(preRelease, "+"); idx >= 0 {
+			preRelease = preRelease[:idx]
+		}
+
+		if preRelease != "" {
+			version.preRelease = strings.Split(preRelease, ".")
+		}
+	}
+
+	return version
+}
+
+// compareTagVersions returns -1, 0 or 1 when 'a' has a lower, equal or higher precedence than 'b'.
+// The numeric groups are compared first, then the pre-release following the SemVer 2.0 rules:
+//	- a version without pre-release has a higher precedence than one with a pre-release
+//	- identifiers are compared one by one, numerically when both are numeric and lexically otherwise
+//	- numeric identifiers have a lower precedence than alphanumeric ones
+//	- a larger set of identifiers has a higher precedence when all the preceding ones are equal
+func compareTagVersions(a, b tagVersion) int {
+	for i := 0; i < len(a.numbers) && i < len(b.numbers); i++ {
+		if c := compareNumeric(a.numbers[i], b.numbers[i]); c != 0 {
+			return c
+		}
+	}
+
+	if c := compareInt(len(a.numbers), len(b.numbers)); c != 0 {
+		return c
+	}
+
+	switch {
+	case len(a.preRelease) == 0 && len(b.preRelease) == 0:
+		return 0
+	case len(a.preRelease) == 0:
+		return 1
+	case len(b.preRelease) == 0:
+		return -1
+	}
+
+	for i := 0; i < len(a.preRelease) && i < len(b.preRelease); i++ {
+		aNum, bNum := isNumeric(a.preRelease[i]), isNumeric(b.preRelease[i])
+
+		var c int
+		switch {
+		case aNum && bNum:
+			c = compareNumeric(a.preRelease[i], b.preRelease[i])
+		case aNum:
+			c = -1
+		case bNum:
+			c = 1
+		default:
+			c = strings.Compare(a.preRelease[i], b.preRelease[i])
+		}
+
+		if c != 0 {
+			return c
+		}
+	}
+
+	return compareInt(len(a.preRelease), len(b.preRelease))
+}
+
+// compareNumeric compares two strings of digits without converting them (no overflow on very large numbers)
+func compareNumeric(a, b string) int {
+	a = strings.TrimLeft(a, "0")
+	b = strings.TrimLeft(b, "0")
+
+	if c := compareInt(len(a), len(b)); c != 0 {
+		return c
+	}
+
+	return strings.Compare(a, b)
+}
+
+func compareInt(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func isNumeric(s string) bool {
+	if s == "" {
+		return false
+	}
+
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+
+	return true
+}
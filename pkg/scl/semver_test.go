@@ -0,0 +1,62 @@
+package scl
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestCompareTagVersions(t *testing.T) {
+	semver := regexp.MustCompile("([0-9]+)\\.([0-9]+)\\.([0-9]+)")
+	semverRC := regexp.MustCompile("([0-9]+)\\.([0-9]+)\\.([0-9]+)-rc\\.([0-9]+)$")
+
+	tests := []struct {
+		r        *regexp.Regexp
+		a        string
+		b        string
+		expected int
+	}{
+		{semver, "refs/tags/1.4.0", "refs/tags/1.3.5", 1},
+		{semver, "refs/tags/1.3.5", "refs/tags/1.4.0", -1},
+		{semver, "refs/tags/1.10.0", "refs/tags/1.9.0", 1},
+		{semver, "refs/tags/2.0.0", "refs/tags/2.0.0", 0},
+		{semver, "refs/tags/2.0.00", "refs/tags/2.0.0", 0},
+		{semver, "refs/tags/1.0.0", "refs/tags/1.0.0-rc.1", 1},
+		{semver, "refs/tags/1.0.0-alpha", "refs/tags/1.0.0-alpha.1", -1},
+		{semver, "refs/tags/1.0.0-alpha.1", "refs/tags/1.0.0-alpha.beta", -1},
+		{semver, "refs/tags/1.0.0-beta", "refs/tags/1.0.0-alpha.beta", 1},
+		{semver, "refs/tags/1.0.0-rc.2", "refs/tags/1.0.0-rc.10", -1},
+		{semver, "refs/tags/1.0.0-rc.1+build.5", "refs/tags/1.0.0-rc.1+build.7", 0},
+		{semverRC, "refs/tags/1.2.3-rc.2", "refs/tags/1.2.3-rc.10", -1},
+		{semverRC, "refs/tags/1.2.4-rc.1", "refs/tags/1.2.3-rc.10", 1},
+	}
+
+	for _, tt := range tests {
+		got := compareTagVersions(parseTagVersion(tt.r, tt.a), parseTagVersion(tt.r, tt.b))
+		if got != tt.expected {
+			t.Errorf("compareTagVersions(%s, %s) wrong result. got=%d, want=%d", tt.a, tt.b, got, tt.expected)
+		}
+	}
+}
+
+func TestParseTagOrder(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected TagOrder
+		fails    bool
+	}{
+		{"date", OrderByDate, false},
+		{"semver", OrderBySemver, false},
+		{"alphabetical", OrderByDate, true},
+	}
+
+	for _, tt := range tests {
+		order, err := ParseTagOrder(tt.input)
+		if (err != nil) != tt.fails {
+			t.Errorf("ParseTagOrder(%s) wrong error. got=%v, fails=%t", tt.input, err, tt.fails)
+		}
+
+		if order != tt.expected {
+			t.Errorf("ParseTagOrder(%s) wrong order. got=%d, want=%d", tt.input, order, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package scl
+
+import (
+	"fmt"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"time"
+)
+
+// GlifTag is a git tag resolved to the commit it points to.
+// Annotated tags keep their *object.Tag while lightweight tags (a simple reference to a commit) only have the commit.
+type GlifTag struct {
+	Name   string
+	Tag    *object.Tag    // nil when the tag is a lightweight tag
+	Commit *object.Commit // The commit targeted by the tag
+}
+
+// IsAnnotated returns true if the tag has its own tag object (created with 'git tag -a')
+func (glifTag *GlifTag) IsAnnotated() bool {
+	return glifTag.Tag != nil
+}
+
+// When returns the date used to order the tags. It is the tagger date for an annotated tag and it falls back to the
+// committer date of the targeted commit for a lightweight tag.
+func (glifTag *GlifTag) When() time.Time {
+	if glifTag.Tag != nil {
+		return glifTag.Tag.Tagger.When
+	}
+
+	return glifTag.Commit.Committer.When
+}
+
+// resolveTag converts a tag reference into a *GlifTag. Both annotated and lightweight tags are supported.
+// An error is returned if the reference does not ultimately point to a commit.
+func (glifRepo *GlifRepo) resolveTag(reference *plumbing.Reference) (*GlifTag, error) {
+	glifTag := &GlifTag{Name: reference.Name().Short()}
+
+	if tagObj, err := glifRepo.GitRepo.TagObject(reference.Hash()); err == nil {
+		commit, err := tagObj.Commit()
+		if err != nil {
+			return nil, err
+		}
+
+		glifTag.Tag = tagObj
+		glifTag.Commit = commit
+
+		return glifTag, nil
+	}
+
+	commit, err := glifRepo.GitRepo.CommitObject(reference.Hash())
+	if err != nil {
+		return nil, err
+	}
+
+	glifTag.Commit = commit
+
+	return glifTag, nil
+}
+
+// CreateTag creates an annotated tag (like 'git tag -a') on the commit and returns it. It fails if the tag already
+// exists.
+func (glifRepo *GlifRepo) CreateTag(name string, hash plumbing.Hash, message string, tagger object.Signature) (*GlifTag, error) {
+	if tagger.Name == "" || tagger.Email == "" {
+		return nil, fmt.Errorf("the name and the email of the tagger are required to create the tag '%s'", name)
+	}
+
+	opts := &git.CreateTagOptions{Tagger: &tagger, Message: message}
+	reference, err := glifRepo.GitRepo.CreateTag(name, hash, opts)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create the tag '%s': %v", name, err)
+	}
+
+	return glifRepo.resolveTag(reference)
+}
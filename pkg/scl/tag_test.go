@@ -0,0 +1,44 @@
+package scl
+
+import (
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCreateTag(t *testing.T) {
+	tr := newTestRepo(t)
+	defer tr.Cleanup()
+
+	head := tr.Commit("ABC-1 first")
+	glifRepo := tr.glifRepo()
+	tagger := object.Signature{Name: "Release Bot", Email: "release@example.com", When: time.Date(2021, 2, 3, 4, 5, 6, 0, time.UTC)}
+
+	tag, err := glifRepo.CreateTag("1.0.0", head, "Release 1.0.0\n\nABC-1\n", tagger)
+	if err != nil {
+		t.Fatalf("CreateTag returned an error: %v", err)
+	}
+
+	if !tag.IsAnnotated() || tag.Commit.Hash != head || tag.Name != "1.0.0" {
+		t.Errorf("wrong tag created. got=%+v", tag)
+	}
+
+	out := tr.Git("cat-file", "-p", "refs/tags/1.0.0")
+	for _, expected := range []string{"object " + head.String(), "tag 1.0.0", "tagger Release Bot <release@example.com> 1612325106 +0000",
+		"\nRelease 1.0.0\n\nABC-1\n"} {
+		if !strings.Contains(out, expected) {
+			t.Errorf("the tag object does not contain %q. got=%q", expected, out)
+		}
+	}
+
+	if _, err := glifRepo.CreateTag("1.0.0", head, "again", tagger); err == nil ||
+		err.Error() != "unable to create the tag '1.0.0': tag already exists" {
+		t.Errorf("CreateTag should fail when the tag exists. got=%v", err)
+	}
+
+	if _, err := glifRepo.CreateTag("1.0.1", head, "no tagger", object.Signature{}); err == nil ||
+		err.Error() != "the name and the email of the tagger are required to create the tag '1.0.1'" {
+		t.Errorf("CreateTag should fail without tagger. got=%v", err)
+	}
+}
@@ -0,0 +1,98 @@
+package scl
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Trailer is a 'Key: value' line at the end of a commit message (ex: 'Signed-off-by: ...', 'Refs: ABC-12')
+type Trailer struct {
+	Key   string
+	Value string
+}
+
+var (
+	trailerRegex = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9-]*)[ \t]*:[ \t]*(.*)$`)
+
+	// Prefixes of the lines generated by git, a block containing one of them is a trailer block even when it
+	// contains other lines
+	gitGeneratedPrefixes = []string{"Signed-off-by: ", cherryPickedPrefix}
+)
+
+const cherryPickedPrefix = "(cherry picked from commit "
+
+// ParseTrailers returns the trailers of a commit message, following the rules of 'git interpret-trailers':
+//	- the trailers are in the last paragraph of the message, which cannot be the first paragraph (the subject)
+//	- the lines starting with '#' are comments and are ignored
+//	- a line starting with a whitespace continues the value of the previous trailer
+//	- the paragraph is a trailer block if all of its lines are trailers, or if at least 25% of them are trailers and
+//	  one of them was generated by git (ex: 'Signed-off-by: ')
+func ParseTrailers(message string) []Trailer {
+	lines := strings.Split(strings.Replace(message, "\r\n", "\n", -1), "\n")
+
+	// Ignore the comments and the blank lines at the end of the message
+	content := make([]string, 0, len(lines))
+	for _, line := range lines {
+		if !strings.HasPrefix(line, "#") {
+			content = append(content, line)
+		}
+	}
+	for len(content) > 0 && strings.TrimSpace(content[len(content)-1]) == "" {
+		content = content[:len(content)-1]
+	}
+
+	start := len(content)
+	for start > 0 && strings.TrimSpace(content[start-1]) != "" {
+		start--
+	}
+
+	// The last paragraph is the first one: there is no trailer block in a message without body
+	if start == 0 {
+		return []Trailer{}
+	}
+
+	return parseTrailerBlock(content[start:])
+}
+
+func parseTrailerBlock(block []string) []Trailer {
+	trailers := make([]Trailer, 0)
+	trailerLines, otherLines := 0, 0
+	generated := false
+	continuable := false
+
+	for _, line := range block {
+		if (line[0] == ' ' || line[0] == '\t') && continuable {
+			last := &trailers[len(trailers)-1]
+			last.Value = strings.TrimSpace(last.Value + " " + strings.TrimSpace(line))
+			continue
+		}
+
+		for _, prefix := range gitGeneratedPrefixes {
+			if strings.HasPrefix(line, prefix) {
+				generated = true
+			}
+		}
+
+		// Generated by 'git cherry-pick -x', it counts as a trailer without being one
+		if strings.HasPrefix(line, cherryPickedPrefix) {
+			trailerLines++
+			continuable = false
+			continue
+		}
+
+		if match := trailerRegex.FindStringSubmatch(line); match != nil {
+			trailers = append(trailers, Trailer{Key: match[1], Value: strings.TrimSpace(match[2])})
+			trailerLines++
+			continuable = true
+		} else {
+			otherLines++
+			continuable = false
+		}
+	}
+
+	if otherLines == 0 || (generated && trailerLines*3 >= otherLines) {
+		return trailers
+	}
+
+	return []Trailer{}
+}
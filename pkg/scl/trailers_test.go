@@ -0,0 +1,34 @@
+package scl
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestParseTrailers(t *testing.T) {
+	tests := []struct {
+		message  string
+		expected []Trailer
+	}{
+		{"Refs: ABC-1", []Trailer{}},
+		{"Subject\n\nRefs: ABC-1\nFixes: XYZ-9\n", []Trailer{{"Refs", "ABC-1"}, {"Fixes", "XYZ-9"}}},
+		{"Subject\n\nBody mentioning ABC-2\n\nJira:ABC-13\n\n", []Trailer{{"Jira", "ABC-13"}}},
+		{"Subject\n\nRefs: ABC-1\n\nBody after the trailers\n", []Trailer{}},
+		{"Subject\n\nNot a trailer\nRefs: ABC-1\n", []Trailer{}},
+		{"Subject\n\nRefs: ABC-1,\n  ABC-2\nFixes : XYZ-9\n# Comment: ignored\n",
+			[]Trailer{{"Refs", "ABC-1, ABC-2"}, {"Fixes", "XYZ-9"}}},
+		{"Subject\n\nSome text\nRefs: ABC-1\nSigned-off-by: Jane <jane@example.com>\n",
+			[]Trailer{{"Refs", "ABC-1"}, {"Signed-off-by", "Jane <jane@example.com>"}}},
+		{"Subject\n\nline 1\nline 2\nline 3\nline 4\nline 5\nline 6\nline 7\nSigned-off-by: Jane\n", []Trailer{}},
+		{"Subject\n\nRefs: ABC-1\n(cherry picked from commit 0123456789)\nsome text\n", []Trailer{{"Refs", "ABC-1"}}},
+		{"Subject\r\n\r\nRefs: ABC-1\r\n", []Trailer{{"Refs", "ABC-1"}}},
+		{"Subject\n\nText with spaces: not a key\n", []Trailer{}},
+	}
+
+	for _, tt := range tests {
+		got := ParseTrailers(tt.message)
+		if fmt.Sprint(got) != fmt.Sprint(tt.expected) {
+			t.Errorf("ParseTrailers(%q) is wrong. got=%v, want=%v", tt.message, got, tt.expected)
+		}
+	}
+}
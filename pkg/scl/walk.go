@@ -0,0 +1,221 @@
+package scl
+
+import (
+	"container/heap"
+	"fmt"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// DiffMode defines which commits of a CommitRange are considered by a diff
+type DiffMode int
+
+// Definition of the supported diff modes
+//	- DiffRange: commits reachable from 'to' but not from 'from' (same as 'git rev-list from..to')
+//	- DiffSymmetric: commits reachable from either 'from' or 'to' but not from both (same as 'git rev-list from...to')
+const (
+	DiffRange DiffMode = iota
+	DiffSymmetric
+)
+
+// ParseDiffMode converts the name of a mode ("range" or "symmetric") to its DiffMode
+func ParseDiffMode(name string) (DiffMode, error) {
+	switch name {
+	case "range":
+		return DiffRange, nil
+	case "symmetric":
+		return DiffSymmetric, nil
+	default:
+		return DiffRange, fmt.Errorf("unknown diff mode '%s' (expected 'range' or 'symmetric')", name)
+	}
+}
+
+// CommitRange contains the commits that are reachable from only one side of a walk between two commits.
+// Both slices are ordered from the most recent to the oldest commit (committer date).
+type CommitRange struct {
+	ToOnly   []*object.Commit // Reachable from 'to' but not from 'from'
+	FromOnly []*object.Commit // Reachable from 'from' but not from 'to'
+}
+
+// Commits returns the commits of the range that are relevant for the specified mode
+func (commitRange *CommitRange) Commits(mode DiffMode) []*object.Commit {
+	if mode == DiffSymmetric {
+		commits := make([]*object.Commit, 0, len(commitRange.ToOnly)+len(commitRange.FromOnly))
+		commits = append(commits, commitRange.ToOnly...)
+		return append(commits, commitRange.FromOnly...)
+	}
+
+	return commitRange.ToOnly
+}
+
+// WalkRange computes the commits reachable from only one of the two specified commits.
+// The history is walked from both commits at the same time, most recent commit first, and every commit is painted with
+// the side(s) it is reachable from. The walk stops as soon as only common ancestors are left to visit, so the cost
+// depends on the size of the range rather than on the size of the whole history.
+// A zero 'from' hash means that every commit reachable from 'to' is part of the range.
+func (glifRepo *GlifRepo) WalkRange(from, to plumbing.Hash) (*CommitRange, error) {
+	walker := newRangeWalker(glifRepo.GitRepo)
+
+	if !from.IsZero() {
+		if _, err := walker.push(from, reachableFromFrom); err != nil {
+			return nil, err
+		}
+	}
+
+	if _, err := walker.push(to, reachableFromTo); err != nil {
+		return nil, err
+	}
+
+	if err := walker.walk(); err != nil {
+		return nil, err
+	}
+
+	commitRange := &CommitRange{
+		ToOnly:   make([]*object.Commit, 0),
+		FromOnly: make([]*object.Commit, 0),
+	}
+
+	for _, node := range walker.visited {
+		switch node.flags {
+		case reachableFromTo:
+			commitRange.ToOnly = append(commitRange.ToOnly, node.commit)
+		case reachableFromFrom:
+			commitRange.FromOnly = append(commitRange.FromOnly, node.commit)
+		}
+	}
+
+	return commitRange, nil
+}
+
+// Flags used to paint the commits during the walk
+const (
+	reachableFromFrom uint8 = 1 << iota
+	reachableFromTo
+
+	reachableFromBoth = reachableFromFrom | reachableFromTo
+)
+
+// walkSlop is the number of extra commits visited once only common ancestors are left in the queue. Like git does, it
+// gives a chance to commits with a skewed committer date to be painted properly.
+const walkSlop = 5
+
+type walkNode struct {
+	commit    *object.Commit
+	flags     uint8
+	parents   []*walkNode
+	queued    bool
+	processed bool
+}
+
+// walkQueue is a priority queue (container/heap) of nodes ordered by committer date, most recent first
+type walkQueue []*walkNode
+
+func (q walkQueue) Len() int { return len(q) }
+func (q walkQueue) Less(i, j int) bool {
+	return q[i].commit.Committer.When.After(q[j].commit.Committer.When)
+}
+func (q walkQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *walkQueue) Push(x interface{}) { *q = append(*q, x.(*walkNode)) }
+func (q *walkQueue) Pop() interface{} {
+	old := *q
+	node := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return node
+}
+
+type rangeWalker struct {
+	repo    *git.Repository
+	nodes   map[plumbing.Hash]*walkNode
+	queue   walkQueue
+	visited []*walkNode
+	pending int // Number of queued nodes that are not yet known to be reachable from both sides
+}
+
+func newRangeWalker(repo *git.Repository) *rangeWalker {
+	return &rangeWalker{
+		repo:    repo,
+		nodes:   make(map[plumbing.Hash]*walkNode),
+		queue:   make(walkQueue, 0),
+		visited: make([]*walkNode, 0),
+	}
+}
+
+// push adds the commit to the queue if it was never seen before and paints it with the specified flags
+func (walker *rangeWalker) push(hash plumbing.Hash, flags uint8) (*walkNode, error) {
+	node, seen := walker.nodes[hash]
+	if !seen {
+		commit, err := walker.repo.CommitObject(hash)
+		if err != nil {
+			return nil, fmt.Errorf("unable to load commit %s: %v", hash, err)
+		}
+
+		node = &walkNode{commit: commit, queued: true}
+		walker.nodes[hash] = node
+		heap.Push(&walker.queue, node)
+		walker.pending++
+	}
+
+	walker.paint(node, flags)
+
+	return node, nil
+}
+
+// paint adds the flags to the node. If the node was already processed the new flags are propagated to all of its
+// known ancestors.
+func (walker *rangeWalker) paint(node *walkNode, flags uint8) {
+	stack := []*walkNode{node}
+
+	for len(stack) > 0 {
+		current := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		if current.flags|flags == current.flags {
+			continue
+		}
+
+		current.flags |= flags
+		if current.queued && current.flags == reachableFromBoth {
+			walker.pending--
+		}
+
+		if current.processed {
+			stack = append(stack, current.parents...)
+		}
+	}
+}
+
+func (walker *rangeWalker) walk() error {
+	slop := walkSlop
+
+	for walker.queue.Len() > 0 {
+		if walker.pending == 0 {
+			if slop == 0 {
+				break
+			}
+			slop--
+		} else {
+			slop = walkSlop
+		}
+
+		node := heap.Pop(&walker.queue).(*walkNode)
+		node.queued = false
+		if node.flags != reachableFromBoth {
+			walker.pending--
+		}
+
+		node.processed = true
+		walker.visited = append(walker.visited, node)
+
+		for _, parentHash := range node.commit.ParentHashes {
+			parent, err := walker.push(parentHash, node.flags)
+			if err != nil {
+				return err
+			}
+
+			node.parents = append(node.parents, parent)
+		}
+	}
+
+	return nil
+}
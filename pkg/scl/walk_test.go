@@ -0,0 +1,284 @@
+package scl
+
+import (
+	"fmt"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/storage/memory"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+// graphBuilder generates commits (all sharing the same empty tree) directly in an in-memory repository. It allows to
+// build arbitrary histories quickly for the tests and benchmarks of the walk.
+type graphBuilder struct {
+	tb    testing.TB
+	repo  *git.Repository
+	tree  plumbing.Hash
+	clock time.Time
+}
+
+func newGraphBuilder(tb testing.TB) *graphBuilder {
+	repo, err := git.Init(memory.NewStorage(), nil)
+	if err != nil {
+		tb.Fatalf("unable to init repository: %v", err)
+	}
+
+	g := &graphBuilder{tb: tb, repo: repo, clock: time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)}
+	g.tree = g.store(&object.Tree{})
+
+	return g
+}
+
+func (g *graphBuilder) store(encoder interface {
+	Encode(plumbing.EncodedObject) error
+}) plumbing.Hash {
+	obj := g.repo.Storer.NewEncodedObject()
+	if err := encoder.Encode(obj); err != nil {
+		g.tb.Fatalf("unable to encode object: %v", err)
+	}
+
+	hash, err := g.repo.Storer.SetEncodedObject(obj)
+	if err != nil {
+		g.tb.Fatalf("unable to store object: %v", err)
+	}
+
+	return hash
+}
+
+func (g *graphBuilder) commit(message string, parents ...plumbing.Hash) plumbing.Hash {
+	g.clock = g.clock.Add(time.Minute)
+	signature := object.Signature{Name: "glif", Email: "glif@example.com", When: g.clock}
+
+	return g.store(&object.Commit{
+		Author:       signature,
+		Committer:    signature,
+		Message:      message,
+		TreeHash:     g.tree,
+		ParentHashes: parents,
+	})
+}
+
+// chain adds 'count' commits on top of 'parent' (which may be the zero hash) and returns the last one
+func (g *graphBuilder) chain(prefix string, count int, parent plumbing.Hash) plumbing.Hash {
+	for i := 0; i < count; i++ {
+		if parent.IsZero() {
+			parent = g.commit(fmt.Sprintf("%s %d", prefix, i))
+		} else {
+			parent = g.commit(fmt.Sprintf("%s %d", prefix, i), parent)
+		}
+	}
+
+	return parent
+}
+
+func (g *graphBuilder) glifRepo() *GlifRepo {
+	return &GlifRepo{GitRepo: g.repo}
+}
+
+func commitMessages(commits []*object.Commit) []string {
+	messages := make([]string, 0, len(commits))
+	for _, c := range commits {
+		messages = append(messages, c.Message)
+	}
+
+	return messages
+}
+
+func sameMessages(got, want []string) bool {
+	if len(got) != len(want) {
+		return false
+	}
+
+	for i := range got {
+		if got[i] != want[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func TestWalkRange(t *testing.T) {
+	g := newGraphBuilder(t)
+
+	base := g.commit("base")
+	release := g.commit("release fix", base)
+	main1 := g.commit("main 1", base)
+	feature := g.commit("feature", base)
+	main2 := g.commit("main 2", main1)
+	merge := g.commit("merge feature", main2, feature)
+
+	tests := []struct {
+		from     plumbing.Hash
+		to       plumbing.Hash
+		mode     DiffMode
+		expected []string
+	}{
+		{base, main2, DiffRange, []string{"main 2", "main 1"}},
+		{main2, base, DiffRange, []string{}},
+		{release, main2, DiffRange, []string{"main 2", "main 1"}},
+		{release, main2, DiffSymmetric, []string{"main 2", "main 1", "release fix"}},
+		{main1, merge, DiffRange, []string{"merge feature", "main 2", "feature"}},
+		{plumbing.ZeroHash, main1, DiffRange, []string{"main 1", "base"}},
+		{merge, merge, DiffSymmetric, []string{}},
+	}
+
+	for _, tt := range tests {
+		commitRange, err := g.glifRepo().WalkRange(tt.from, tt.to)
+		if err != nil {
+			t.Fatalf("WalkRange returned an error: %v", err)
+		}
+
+		got := commitMessages(commitRange.Commits(tt.mode))
+		if !sameMessages(got, tt.expected) {
+			t.Errorf("WalkRange(%s, %s) mode %d wrong commits. got=%v, want=%v", tt.from, tt.to, tt.mode, got, tt.expected)
+		}
+	}
+}
+
+func TestWalkRangeUnknownCommit(t *testing.T) {
+	g := newGraphBuilder(t)
+	base := g.commit("base")
+
+	if _, err := g.glifRepo().WalkRange(base, plumbing.NewHash("0123456789012345678901234567890123456789")); err == nil {
+		t.Errorf("WalkRange should fail when a commit does not exist")
+	}
+}
+
+// TestWalkRangeMatchesReachability compares the walk with a naive computation of the reachable sets on random
+// histories containing branches and merges.
+func TestWalkRangeMatchesReachability(t *testing.T) {
+	for seed := int64(1); seed <= 20; seed++ {
+		g := newGraphBuilder(t)
+		commits := generateRandomHistory(g, rand.New(rand.NewSource(seed)), 200)
+
+		from, to := commits[len(commits)/2], commits[len(commits)-1]
+		commitRange, err := g.glifRepo().WalkRange(from, to)
+		if err != nil {
+			t.Fatalf("WalkRange returned an error: %v", err)
+		}
+
+		fromSet := reachable(t, g.repo, from)
+		toSet := reachable(t, g.repo, to)
+
+		expectedToOnly, expectedFromOnly := 0, 0
+		for hash := range toSet {
+			if !fromSet[hash] {
+				expectedToOnly++
+			}
+		}
+		for hash := range fromSet {
+			if !toSet[hash] {
+				expectedFromOnly++
+			}
+		}
+
+		for _, c := range commitRange.ToOnly {
+			if !toSet[c.Hash] || fromSet[c.Hash] {
+				t.Errorf("seed %d: commit '%s' should not be in ToOnly", seed, c.Message)
+			}
+		}
+		for _, c := range commitRange.FromOnly {
+			if !fromSet[c.Hash] || toSet[c.Hash] {
+				t.Errorf("seed %d: commit '%s' should not be in FromOnly", seed, c.Message)
+			}
+		}
+
+		if len(commitRange.ToOnly) != expectedToOnly || len(commitRange.FromOnly) != expectedFromOnly {
+			t.Errorf("seed %d: wrong number of commits. got=%d/%d, want=%d/%d", seed,
+				len(commitRange.ToOnly), len(commitRange.FromOnly), expectedToOnly, expectedFromOnly)
+		}
+	}
+}
+
+// generateRandomHistory creates a history where each new commit either extends one of the existing heads, starts a
+// new branch from a random commit or merges two heads together. The returned slice is in creation order.
+func generateRandomHistory(g *graphBuilder, r *rand.Rand, size int) []plumbing.Hash {
+	commits := []plumbing.Hash{g.commit("root")}
+	heads := []plumbing.Hash{commits[0]}
+
+	for i := 1; i < size; i++ {
+		var hash plumbing.Hash
+		message := fmt.Sprintf("commit %d", i)
+
+		switch choice := r.Intn(10); {
+		case choice < 6:
+			idx := r.Intn(len(heads))
+			hash = g.commit(message, heads[idx])
+			heads[idx] = hash
+		case choice < 8:
+			hash = g.commit(message, commits[r.Intn(len(commits))])
+			heads = append(heads, hash)
+		default:
+			a, b := r.Intn(len(heads)), r.Intn(len(heads))
+			if a == b {
+				hash = g.commit(message, heads[a])
+			} else {
+				hash = g.commit(message, heads[a], heads[b])
+			}
+			heads[a] = hash
+		}
+
+		commits = append(commits, hash)
+	}
+
+	return commits
+}
+
+func reachable(t *testing.T, repo *git.Repository, from plumbing.Hash) map[plumbing.Hash]bool {
+	set := make(map[plumbing.Hash]bool)
+
+	iter, err := repo.Log(&git.LogOptions{From: from})
+	if err != nil {
+		t.Fatalf("unable to retrieve log: %v", err)
+	}
+
+	_ = iter.ForEach(func(c *object.Commit) error {
+		set[c.Hash] = true
+		return nil
+	})
+
+	return set
+}
+
+// benchmarkWalkRange generates a linear history of 'size' commits followed by a release branch and a main branch,
+// each with 'divergence' commits, and walks the range between the tips of both branches.
+func benchmarkWalkRange(b *testing.B, size, divergence int, mode DiffMode) {
+	g := newGraphBuilder(b)
+
+	base := g.chain("history", size, plumbing.ZeroHash)
+	from := g.chain("release", divergence, base)
+	to := g.chain("main", divergence, base)
+	glifRepo := g.glifRepo()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		commitRange, err := glifRepo.WalkRange(from, to)
+		if err != nil {
+			b.Fatalf("WalkRange returned an error: %v", err)
+		}
+
+		if got := len(commitRange.Commits(mode)); got == 0 {
+			b.Fatalf("WalkRange returned no commits")
+		}
+	}
+}
+
+func BenchmarkWalkRange1kHistory(b *testing.B) {
+	benchmarkWalkRange(b, 1000, 50, DiffRange)
+}
+
+func BenchmarkWalkRange50kHistory(b *testing.B) {
+	benchmarkWalkRange(b, 50000, 50, DiffRange)
+}
+
+func BenchmarkWalkRangeSymmetric50kHistory(b *testing.B) {
+	benchmarkWalkRange(b, 50000, 50, DiffSymmetric)
+}
+
+func BenchmarkWalkRangeFullHistory(b *testing.B) {
+	benchmarkWalkRange(b, 10000, 10000, DiffSymmetric)
+}
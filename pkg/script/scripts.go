@@ -1,4 +1,13 @@
 // Package script contains the preedefined scripts that are binded to the input flags
+//
+// Each script ends with a call to 'diff', the tickets it returns are the result of the script and are printed by the
+// command line (see the output package).
+//
+// On top of 'repopath' and 'tickets', the scripts rely on these variables that are set from the input flags:
+//	- forcefetch: true when the repository must be fetched before extracting the tags
+//	- fetchopts: the options hash given to the 'fetch' builtin
+//	- diffopts: the options hash given to the 'diff' builtin (ex: the paths to include and exclude)
+//	- tagger: the identity ({"name": ..., "email": ...}) for the 'createTag' and 'addNote' builtins
 package script
 
 // DiffLatestSemverWithLatestBuilds is a predefined script
@@ -7,6 +16,7 @@ var DiffLatestSemverWithLatestBuilds = `
 set repopath ".";
 print("Using repo path: " + whichRepo());
 let repo = initRepo();
+if (forcefetch) { fetch(repo, fetchopts); }
 let version="$.$.$-build.$";
 
 extractTags(repo, version);
@@ -14,7 +24,7 @@ extractTags(repo, version);
 let from = getLatestTag(repo, 1);
 let to = getLatestTag(repo, 0);
 
-diff(repo, from, to);
+diff(repo, from, to, diffopts);
 `
 
 // DiffLatestSemverWithLatestRCs is a predefined script
@@ -23,6 +33,7 @@ var DiffLatestSemverWithLatestRCs = `
 set repopath ".";
 print("Using repo path: " + whichRepo());
 let repo = initRepo();
+if (forcefetch) { fetch(repo, fetchopts); }
 let version="$.$.$-rc.$";
 
 extractTags(repo, version);
@@ -30,7 +41,7 @@ extractTags(repo, version);
 let from = getLatestTag(repo, 1);
 let to = getLatestTag(repo, 0);
 
-diff(repo, from, to);
+diff(repo, from, to, diffopts);
 `
 
 // DiffLatestSemver is a predefined script
@@ -39,6 +50,7 @@ var DiffLatestSemver = `
 set repopath "."
 print("Using repo path: " + whichRepo());
 let repo = initRepo();
+if (forcefetch) { fetch(repo, fetchopts); }
 let version="$.$.$";
 
 extractTags(repo, version);
@@ -46,5 +58,24 @@ extractTags(repo, version);
 let from = getLatestTag(repo, 1);
 let to = getLatestTag(repo, 0);
 
-diff(repo, from, to);
+diff(repo, from, to, diffopts);
+`
+
+// DiffLatestSemverToHead is a predefined script
+// It performs a diff between the latest release (production) version (MAJOR.MINOR.PATCH) and the current HEAD.
+// The versions are ordered by semantic version so that a hotfix of a previous version is never considered the latest.
+// When no version was released yet, every commit reachable from HEAD is part of the diff.
+var DiffLatestSemverToHead = `
+set repopath ".";
+print("Using repo path: " + whichRepo());
+let repo = initRepo();
+if (forcefetch) { fetch(repo, fetchopts); }
+let version="$.$.$";
+
+extractTags(repo, version, "semver");
+
+let from = getLatestTag(repo, 0);
+let to = getRevision(repo, "HEAD");
+
+diff(repo, from, to, diffopts);
 `